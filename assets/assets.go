@@ -0,0 +1,171 @@
+// Package assets classifies release assets by operating system, CPU
+// architecture and package format based on their file names.
+//
+// Classification is rule based. A rule is a regular expression matched
+// against the lower-cased file name together with the OS, architecture
+// and/or format it implies. Rules are tried in order and the first match
+// sets each field, so custom rules placed before the defaults override
+// them.
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Formats that describe other assets rather than being installable
+// themselves
+const (
+	FormatChecksum  = "checksum"
+	FormatSignature = "signature"
+	FormatSBOM      = "sbom"
+)
+
+// Unknown is reported for fields no rule matched.
+const Unknown = "unknown"
+
+// Rule maps file names matching Pattern to an OS, architecture and format.
+// Empty fields leave the classification to later rules.
+type Rule struct {
+	Pattern string `json:"pattern"`
+	OS      string `json:"os,omitempty"`
+	Arch    string `json:"arch,omitempty"`
+	Format  string `json:"format,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// Classification is what the rules determined about an asset. Fields no
+// rule matched are empty.
+type Classification struct {
+	OS     string `json:"os,omitempty"`
+	Arch   string `json:"arch,omitempty"`
+	Format string `json:"format,omitempty"`
+}
+
+// IsVerification reports whether the asset is a checksum or signature file,
+// which are downloaded alongside other assets rather than on their own.
+func (c Classification) IsVerification() bool {
+	return c.Format == FormatChecksum || c.Format == FormatSignature
+}
+
+// token matches any of the alternatives as a whole word of a file name,
+// delimited by punctuation or the ends of the name.
+func token(alternatives string) string {
+	return `(?:^|[^a-z0-9])(?:` + alternatives + `)(?:[^a-z0-9]|$)`
+}
+
+// DefaultRules recognize the naming conventions of common release tooling
+// such as GoReleaser, cargo-dist and electron-builder.
+var DefaultRules = []Rule{
+	// Verification and metadata files come first so sha256sums for a
+	// linux build are not counted as a linux download
+	{Pattern: token(`(?:sha(?:1|256|512)|md5)?sums?|checksums?`) + `|\.(?:sha1|sha256|sha512|md5)(?:sum)?$`, Format: FormatChecksum},
+	{Pattern: `\.(?:sig|asc|pem|minisig|crt|cert)$|\.sigstore(?:\.json)?$`, Format: FormatSignature},
+	{Pattern: token(`sbom`) + `|\.spdx(?:\.json)?$|\.cdx\.(?:json|xml)$`, Format: FormatSBOM},
+
+	{Pattern: token(`linux`), OS: "linux"},
+	{Pattern: token(`darwin|macos|osx|mac|apple`), OS: "darwin"},
+	{Pattern: token(`windows|win32|win64|win`), OS: "windows"},
+	{Pattern: token(`freebsd`), OS: "freebsd"},
+	{Pattern: token(`openbsd`), OS: "openbsd"},
+	{Pattern: token(`netbsd`), OS: "netbsd"},
+	{Pattern: token(`android`), OS: "android"},
+
+	{Pattern: token(`amd64|x86_64|x86-64|x64`), Arch: "amd64"},
+	{Pattern: token(`arm64|aarch64`), Arch: "arm64"},
+	{Pattern: token(`armv[5-7]l?|armhf|armel|arm`), Arch: "arm"},
+	{Pattern: token(`386|i386|i686|x86|32-?bit`), Arch: "386"},
+	{Pattern: token(`ppc64le`), Arch: "ppc64le"},
+	{Pattern: token(`s390x`), Arch: "s390x"},
+	{Pattern: token(`riscv64`), Arch: "riscv64"},
+	{Pattern: token(`universal|universal2`), Arch: "universal"},
+
+	{Pattern: `\.(?:tar\.gz|tgz)$`, Format: "tar.gz"},
+	{Pattern: `\.(?:tar\.xz|txz)$`, Format: "tar.xz"},
+	{Pattern: `\.(?:tar\.bz2|tbz2?)$`, Format: "tar.bz2"},
+	{Pattern: `\.tar\.zst$`, Format: "tar.zst"},
+	{Pattern: `\.zip$`, Format: "zip"},
+	{Pattern: `\.deb$`, OS: "linux", Format: "deb"},
+	{Pattern: `\.rpm$`, OS: "linux", Format: "rpm"},
+	{Pattern: `\.apk$`, OS: "linux", Format: "apk"},
+	{Pattern: `\.appimage$`, OS: "linux", Format: "appimage"},
+	{Pattern: `\.snap$`, OS: "linux", Format: "snap"},
+	{Pattern: `\.msi$`, OS: "windows", Format: "msi"},
+	{Pattern: `\.exe$`, OS: "windows", Format: "exe"},
+	{Pattern: `\.dmg$`, OS: "darwin", Format: "dmg"},
+	{Pattern: `\.pkg$`, OS: "darwin", Format: "pkg"},
+	{Pattern: `\.gz$`, Format: "gz"},
+	{Pattern: `\.jar$`, Format: "jar"},
+	{Pattern: `\.whl$`, Format: "wheel"},
+	{Pattern: `\.json$`, Format: "json"},
+	{Pattern: `\.txt$`, Format: "txt"},
+}
+
+// Classifier applies an ordered list of rules to asset names.
+type Classifier struct {
+	rules []Rule
+}
+
+// NewClassifier returns a Classifier that tries rules before DefaultRules.
+func NewClassifier(rules []Rule) (*Classifier, error) {
+	c := &Classifier{rules: make([]Rule, 0, len(rules)+len(DefaultRules))}
+	for _, rule := range slices.Concat(rules, DefaultRules) {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset rule %q: %v", rule.Pattern, err)
+		}
+		rule.re = re
+		c.rules = append(c.rules, rule)
+	}
+	return c, nil
+}
+
+// Classify determines the OS, architecture and format of the asset name.
+// Platform specific assets without a recognized format are reported as
+// bare binaries.
+func (c *Classifier) Classify(name string) Classification {
+	name = strings.ToLower(name)
+
+	var class Classification
+	for _, rule := range c.rules {
+		if class.OS != "" && class.Arch != "" && class.Format != "" {
+			break
+		}
+		if !rule.re.MatchString(name) {
+			continue
+		}
+		if class.OS == "" {
+			class.OS = rule.OS
+		}
+		if class.Arch == "" {
+			class.Arch = rule.Arch
+		}
+		if class.Format == "" {
+			class.Format = rule.Format
+		}
+	}
+
+	if class.Format == "" && (class.OS != "" || class.Arch != "") {
+		class.Format = "binary"
+	}
+	return class
+}
+
+// LoadRules reads a JSON array of rules from path.
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading asset rules: %v", err)
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("error parsing asset rules: %v", err)
+	}
+	return rules, nil
+}
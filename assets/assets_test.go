@@ -0,0 +1,66 @@
+package assets
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	c, err := NewClassifier(nil)
+	if err != nil {
+		t.Fatalf("NewClassifier returned error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want Classification
+	}{
+		{"keploy_linux_amd64.tar.gz", Classification{OS: "linux", Arch: "amd64", Format: "tar.gz"}},
+		{"keploy_darwin_arm64.tar.gz", Classification{OS: "darwin", Arch: "arm64", Format: "tar.gz"}},
+		{"keploy_darwin_all.tar.gz", Classification{OS: "darwin", Format: "tar.gz"}},
+		{"keploy-x86_64-unknown-linux-gnu.tar.xz", Classification{OS: "linux", Arch: "amd64", Format: "tar.xz"}},
+		{"keploy_windows_386.zip", Classification{OS: "windows", Arch: "386", Format: "zip"}},
+		{"keploy_1.2.3_armhf.deb", Classification{OS: "linux", Arch: "arm", Format: "deb"}},
+		{"keploy-1.2.3.aarch64.rpm", Classification{OS: "linux", Arch: "arm64", Format: "rpm"}},
+		{"Keploy-Setup-x64.msi", Classification{OS: "windows", Arch: "amd64", Format: "msi"}},
+		{"keploy-linux-amd64", Classification{OS: "linux", Arch: "amd64", Format: "binary"}},
+		{"checksums.txt", Classification{Format: FormatChecksum}},
+		{"keploy_1.2.3_SHA256SUMS", Classification{Format: FormatChecksum}},
+		{"keploy_linux_amd64.tar.gz.sha256", Classification{OS: "linux", Arch: "amd64", Format: FormatChecksum}},
+		{"keploy_linux_amd64.tar.gz.sig", Classification{OS: "linux", Arch: "amd64", Format: FormatSignature}},
+		{"keploy.spdx.json", Classification{Format: FormatSBOM}},
+		{"README.md", Classification{}},
+	}
+
+	for _, tt := range tests {
+		if got := c.Classify(tt.name); got != tt.want {
+			t.Errorf("Classify(%q) = %+v, expected %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyCustomRules(t *testing.T) {
+	c, err := NewClassifier([]Rule{
+		{Pattern: `-mac\.`, OS: "darwin", Arch: "universal"},
+		{Pattern: `\.vsix$`, Format: "vsix"},
+	})
+	if err != nil {
+		t.Fatalf("NewClassifier returned error: %v", err)
+	}
+
+	if got := c.Classify("keploy-mac.zip"); got != (Classification{OS: "darwin", Arch: "universal", Format: "zip"}) {
+		t.Errorf("Unexpected classification %+v", got)
+	}
+	if got := c.Classify("keploy-1.0.0.vsix"); got.Format != "vsix" {
+		t.Errorf("Expected format vsix, got %q", got.Format)
+	}
+
+	if _, err := NewClassifier([]Rule{{Pattern: "("}}); err == nil {
+		t.Errorf("Expected error for an invalid pattern")
+	}
+}
+
+func TestIsVerification(t *testing.T) {
+	for format, want := range map[string]bool{FormatChecksum: true, FormatSignature: true, FormatSBOM: false, "tar.gz": false} {
+		if got := (Classification{Format: format}).IsVerification(); got != want {
+			t.Errorf("IsVerification for %q = %v, expected %v", format, got, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Package bots tells automation accounts such as dependabot, renovate and
+// CI users apart from the people contributing to a repository.
+//
+// An account is a bot if GitHub reports its type as Bot, if its login ends
+// in [bot] like those of GitHub Apps, or if it is listed in or matches the
+// configured rules. Accounts listed as humans are never bots, which lets
+// a rule's exceptions be spelled out.
+package bots
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// TypeBot is the user type GitHub reports for bot accounts.
+const TypeBot = "Bot"
+
+// Rules configure a Classifier. Logins are matched case-insensitively;
+// patterns are regular expressions matched against the lower-cased login.
+type Rules struct {
+	// Bots are logins always classified as bots, e.g. CI accounts
+	Bots []string `json:"bots,omitempty"`
+	// Humans are logins never classified as bots
+	Humans []string `json:"humans,omitempty"`
+	// Patterns match the logins of further bots
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// DefaultPatterns recognize well-known bots that also commit under plain
+// user accounts or without the [bot] suffix.
+var DefaultPatterns = []string{
+	`^dependabot(?:-preview)?$`,
+	`^renovate(?:-bot)?$`,
+	`^github-actions$`,
+	`^greenkeeper(?:io)?$`,
+	`^snyk-bot$`,
+	`^codecov-(?:io|commenter)$`,
+	`^pre-commit-ci$`,
+	`^allcontributors$`,
+}
+
+// Classifier decides whether accounts are bots.
+type Classifier struct {
+	bots     map[string]struct{}
+	humans   map[string]struct{}
+	patterns []*regexp.Regexp
+}
+
+// NewClassifier returns a Classifier applying rules together with
+// DefaultPatterns.
+func NewClassifier(rules Rules) (*Classifier, error) {
+	c := &Classifier{
+		bots:   make(map[string]struct{}, len(rules.Bots)),
+		humans: make(map[string]struct{}, len(rules.Humans)),
+	}
+	for _, login := range rules.Bots {
+		c.bots[strings.ToLower(login)] = struct{}{}
+	}
+	for _, login := range rules.Humans {
+		c.humans[strings.ToLower(login)] = struct{}{}
+	}
+	for _, pattern := range slices.Concat(rules.Patterns, DefaultPatterns) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bot pattern %q: %v", pattern, err)
+		}
+		c.patterns = append(c.patterns, re)
+	}
+	return c, nil
+}
+
+// IsBot reports whether the account with the given login and GitHub user
+// type is a bot. The type may be empty when it is not known, such as for
+// commit authors.
+func (c *Classifier) IsBot(login, userType string) bool {
+	login = strings.ToLower(strings.TrimSpace(login))
+	if login == "" && userType == "" {
+		return false
+	}
+	if _, ok := c.humans[login]; ok {
+		return false
+	}
+	if _, ok := c.bots[login]; ok {
+		return true
+	}
+	if userType == TypeBot || strings.HasSuffix(login, "[bot]") {
+		return true
+	}
+	for _, re := range c.patterns {
+		if re.MatchString(login) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadRules reads a JSON object of rules from path.
+func LoadRules(path string) (Rules, error) {
+	var rules Rules
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rules, fmt.Errorf("error reading bot rules: %v", err)
+	}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return rules, fmt.Errorf("error parsing bot rules: %v", err)
+	}
+	return rules, nil
+}
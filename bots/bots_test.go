@@ -0,0 +1,68 @@
+package bots
+
+import "testing"
+
+func TestIsBot(t *testing.T) {
+	c, err := NewClassifier(Rules{})
+	if err != nil {
+		t.Fatalf("NewClassifier returned error: %v", err)
+	}
+
+	tests := []struct {
+		login    string
+		userType string
+		want     bool
+	}{
+		{"dependabot[bot]", "Bot", true},
+		{"renovate[bot]", "", true},
+		{"some-app", "Bot", true},
+		{"dependabot", "User", true},
+		{"Dependabot-Preview", "", true},
+		{"github-actions", "", true},
+		{"renovate-bot", "", true},
+		{"alice", "User", false},
+		{"renovatehq", "", false},
+		{"robot", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := c.IsBot(tt.login, tt.userType); got != tt.want {
+			t.Errorf("IsBot(%q, %q) = %v, expected %v", tt.login, tt.userType, got, tt.want)
+		}
+	}
+}
+
+func TestIsBotCustomRules(t *testing.T) {
+	c, err := NewClassifier(Rules{
+		Bots:     []string{"Keploy-CI"},
+		Humans:   []string{"release-bot", "github-actions"},
+		Patterns: []string{`-bot$`},
+	})
+	if err != nil {
+		t.Fatalf("NewClassifier returned error: %v", err)
+	}
+
+	tests := []struct {
+		login string
+		want  bool
+	}{
+		{"keploy-ci", true},
+		{"docs-bot", true},
+		{"release-bot", false},
+		{"github-actions", false},
+		{"alice", false},
+	}
+
+	for _, tt := range tests {
+		if got := c.IsBot(tt.login, ""); got != tt.want {
+			t.Errorf("IsBot(%q) = %v, expected %v", tt.login, got, tt.want)
+		}
+	}
+}
+
+func TestNewClassifierInvalidPattern(t *testing.T) {
+	if _, err := NewClassifier(Rules{Patterns: []string{"("}}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,189 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServerConfig holds the process-wide settings read from the environment
+type ServerConfig struct {
+	// GitHubToken authenticates background work such as snapshots, which
+	// has no caller token to borrow
+	GitHubToken string
+	// GitHubAPIURL is the API root used for repositories on github.com
+	GitHubAPIURL string
+	// EnterpriseHosts maps GitHub Enterprise Server web hosts to their API
+	// roots, e.g. ghe.example.com -> https://ghe.example.com/api/v3
+	EnterpriseHosts map[string]string
+	// CacheBackend selects where GitHub responses are cached for conditional
+	// requests: "memory" (default), "disk" or "none"
+	CacheBackend string
+	// CacheDir is the directory used by the disk cache
+	CacheDir string
+	// CacheSize is the number of responses the memory cache holds
+	CacheSize int
+	// Concurrency bounds how many repositories one request fetches in
+	// parallel
+	Concurrency int
+	// RequestTimeout bounds the GitHub work done for a single API request
+	RequestTimeout time.Duration
+	// EndpointTimeouts overrides RequestTimeout per endpoint path, e.g.
+	// /active-contributors -> 10m
+	EndpointTimeouts map[string]time.Duration
+	// DataDir is where historical snapshots are stored
+	DataDir string
+	// CollectSchedule is the cron expression or @every/@daily descriptor on
+	// which tracked repositories and organizations are refreshed
+	CollectSchedule string
+	// SnapshotRepos lists repository URLs that are always tracked. It is
+	// kept for configurations predating TrackedFile
+	SnapshotRepos []string
+	// PrecomputedMaxAge is how old a collected result may be before
+	// requests fall back to fetching from GitHub; zero disables the limit
+	PrecomputedMaxAge time.Duration
+	// AdminToken authorizes changes to the tracked targets; when empty they
+	// can only be configured through TrackedFile
+	AdminToken string
+	// AssetRulesFile is a JSON file of asset classification rules tried
+	// before the built-in ones
+	AssetRulesFile string
+	// TrackedFile is a JSON file listing targets the collector always
+	// refreshes, in addition to those registered through the API
+	TrackedFile string
+	// MailmapFile lists the logins and commit emails of contributors who
+	// should be counted as one person
+	MailmapFile string
+	// BotRulesFile is a JSON file of bot and human logins and bot login
+	// patterns, applied together with the built-in patterns
+	BotRulesFile string
+}
+
+// LoadServerConfig reads the server settings from GITSTATS_* environment
+// variables, falling back to defaults for anything unset or malformed
+func LoadServerConfig() ServerConfig {
+	config := ServerConfig{
+		GitHubToken:       os.Getenv("GITSTATS_GITHUB_TOKEN"),
+		GitHubAPIURL:      envString("GITSTATS_GITHUB_API_URL", "https://api.github.com/"),
+		EnterpriseHosts:   parseEnterpriseHosts(os.Getenv("GITSTATS_ENTERPRISE_HOSTS")),
+		CacheBackend:      envString("GITSTATS_CACHE", "memory"),
+		CacheDir:          envString("GITSTATS_CACHE_DIR", ".gitstats-cache"),
+		CacheSize:         envInt("GITSTATS_CACHE_SIZE", 1000),
+		Concurrency:       envInt("GITSTATS_CONCURRENCY", 8),
+		RequestTimeout:    envDuration("GITSTATS_REQUEST_TIMEOUT", 2*time.Minute),
+		EndpointTimeouts:  parseEndpointTimeouts(os.Getenv("GITSTATS_ENDPOINT_TIMEOUTS")),
+		DataDir:           envString("GITSTATS_DATA_DIR", ".gitstats-data"),
+		CollectSchedule:   envString("GITSTATS_COLLECT_SCHEDULE", "@daily"),
+		PrecomputedMaxAge: envDuration("GITSTATS_PRECOMPUTED_MAX_AGE", 48*time.Hour),
+		AdminToken:        os.Getenv("GITSTATS_ADMIN_TOKEN"),
+		SnapshotRepos:     envList("GITSTATS_SNAPSHOT_REPOS"),
+		TrackedFile:       os.Getenv("GITSTATS_TRACKED_FILE"),
+		AssetRulesFile:    os.Getenv("GITSTATS_ASSET_RULES"),
+		MailmapFile:       os.Getenv("GITSTATS_MAILMAP"),
+		BotRulesFile:      os.Getenv("GITSTATS_BOT_RULES"),
+	}
+
+	// GITSTATS_SNAPSHOT_INTERVAL predates the collect schedule and still
+	// applies when no schedule is set
+	if _, ok := os.LookupEnv("GITSTATS_COLLECT_SCHEDULE"); !ok {
+		if interval := envDuration("GITSTATS_SNAPSHOT_INTERVAL", 0); interval > 0 {
+			config.CollectSchedule = "@every " + interval.String()
+		}
+	}
+	return config
+}
+
+// LoadTrackedTargets reads the JSON array of targets in path. An empty path
+// yields no targets
+func LoadTrackedTargets(path string) ([]TrackedTarget, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tracked targets: %v", err)
+	}
+
+	var targets []TrackedTarget
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, fmt.Errorf("error parsing tracked targets: %v", err)
+	}
+	return targets, nil
+}
+
+func envString(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func envList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// parseEnterpriseHosts reads a comma separated list of GitHub Enterprise
+// Server hosts. Each entry is either a bare host, whose API is assumed at
+// https://<host>/api/v3, or host=apiURL for non-standard setups
+func parseEnterpriseHosts(value string) map[string]string {
+	hosts := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		host, apiURL, ok := strings.Cut(entry, "=")
+		host = strings.ToLower(strings.TrimSpace(host))
+		if !ok || strings.TrimSpace(apiURL) == "" {
+			apiURL = fmt.Sprintf("https://%s/api/v3", host)
+		}
+		hosts[host] = strings.TrimSpace(apiURL)
+	}
+	return hosts
+}
+
+// parseEndpointTimeouts reads a comma separated list of path=duration
+// entries such as "/star-history=5m,/active-contributors=10m". Malformed
+// entries are skipped
+func parseEndpointTimeouts(value string) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+	for _, entry := range strings.Split(value, ",") {
+		path, duration, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil {
+			continue
+		}
+		timeouts[strings.TrimSpace(path)] = timeout
+	}
+	return timeouts
+}
@@ -10,16 +10,24 @@ type ReleaseAsset struct {
 
 // Release represents a GitHub release
 type Release struct {
-	ID        int            `json:"id"`
-	TagName   string         `json:"tag_name"`
-	CreatedAt time.Time      `json:"created_at"`
-	Assets    []ReleaseAsset `json:"assets"`
+	ID        int       `json:"id"`
+	TagName   string    `json:"tag_name"`
+	CreatedAt time.Time `json:"created_at"`
+	// PublishedAt is nil for drafts. GitHub sets CreatedAt to the date of
+	// the tagged commit, so the difference is the release lead time
+	PublishedAt *time.Time     `json:"published_at"`
+	Draft       bool           `json:"draft"`
+	Prerelease  bool           `json:"prerelease"`
+	Assets      []ReleaseAsset `json:"assets"`
 }
 
 // ReleaseDownloadStats represents download statistics for a single release
 type ReleaseDownloadStats struct {
 	TagName        string       `json:"tag_name"`
 	CreatedAt      time.Time    `json:"created_at"`
+	PublishedAt    *time.Time   `json:"published_at,omitempty"`
+	Draft          bool         `json:"draft,omitempty"`
+	Prerelease     bool         `json:"prerelease,omitempty"`
 	TotalDownloads int          `json:"total_downloads"`
 	Assets         []AssetStats `json:"assets"`
 }
@@ -28,6 +36,10 @@ type ReleaseDownloadStats struct {
 type AssetStats struct {
 	Name          string `json:"name"`
 	DownloadCount int    `json:"download_count"`
+	// OS, Arch and Format are derived from Name when classified
+	OS     string `json:"os,omitempty"`
+	Arch   string `json:"arch,omitempty"`
+	Format string `json:"format,omitempty"`
 }
 
 // DownloadStats represents download statistics for all releases
@@ -35,25 +47,298 @@ type DownloadStats struct {
 	RepoName       string                 `json:"repo_name"`
 	TotalDownloads int                    `json:"total_downloads"`
 	Releases       []ReleaseDownloadStats `json:"releases"`
+	// Lines rolls releases up per major or minor version line on request
+	Lines []VersionLine `json:"lines,omitempty"`
+	// Breakdown aggregates downloads by the classification of assets
+	Breakdown *DownloadBreakdown `json:"breakdown,omitempty"`
+	// Trends is only computed on request, from stored snapshots
+	Trends *DownloadTrends `json:"trends,omitempty"`
+	// Channels lists GitHub Packages linked to the repository on request.
+	// TotalAdoption adds their known downloads to TotalDownloads
+	Channels         []DownloadChannel `json:"channels,omitempty"`
+	ChannelDownloads int               `json:"channel_downloads,omitempty"`
+	TotalAdoption    int               `json:"total_adoption,omitempty"`
+	// ChannelError explains why channels could not be listed, typically a
+	// missing token with the read:packages scope
+	ChannelError string `json:"channel_error,omitempty"`
+}
+
+// VersionLine sums the downloads of the releases of one major or minor
+// version line, such as v1 or v1.2. Tags that are not semantic versions
+// are grouped under "unversioned"
+type VersionLine struct {
+	Line             string    `json:"line"`
+	Releases         int       `json:"releases"`
+	TotalDownloads   int       `json:"total_downloads"`
+	Latest           string    `json:"latest"`
+	FirstReleasedAt  time.Time `json:"first_released_at"`
+	LatestReleasedAt time.Time `json:"latest_released_at"`
+}
+
+// DownloadComparison lines up the release downloads of several repositories
+// on a shared time axis
+type DownloadComparison struct {
+	Interval     string                 `json:"interval"`
+	Buckets      []time.Time            `json:"buckets"`
+	Repositories []RepoDownloadTimeline `json:"repositories"`
+	Errors       []RepoError            `json:"errors,omitempty"`
+}
+
+// RepoDownloadTimeline is one repository of a DownloadComparison. Downloads
+// and Cumulative have one entry per bucket of the comparison
+type RepoDownloadTimeline struct {
+	RepoName       string `json:"repo_name"`
+	TotalDownloads int    `json:"total_downloads"`
+	// Downloads sums the downloads of the releases published in each bucket
+	Downloads  []int          `json:"downloads"`
+	Cumulative []int          `json:"cumulative"`
+	Releases   []ReleasePoint `json:"releases"`
+}
+
+// ReleasePoint is a release placed on a timeline, oldest first
+type ReleasePoint struct {
+	TagName        string    `json:"tag_name"`
+	CreatedAt      time.Time `json:"created_at"`
+	TotalDownloads int       `json:"total_downloads"`
+}
+
+// ReleaseCadence describes how regularly a repository, or a whole
+// organization, publishes releases
+type ReleaseCadence struct {
+	RepoName      string     `json:"repo_name"`
+	Releases      int        `json:"releases"`
+	FirstRelease  *time.Time `json:"first_release,omitempty"`
+	LatestRelease *time.Time `json:"latest_release,omitempty"`
+	// ReleasesPerMonth averages over the months from the first release up
+	// to now; Monthly lists the count of every calendar month in between
+	ReleasesPerMonth  float64        `json:"releases_per_month"`
+	Monthly           []MonthlyCount `json:"monthly"`
+	MeanDaysBetween   float64        `json:"mean_days_between"`
+	MedianDaysBetween float64        `json:"median_days_between"`
+	Gaps              []GapBucket    `json:"gaps"`
+	// MeanLeadTimeHours and MedianLeadTimeHours measure the time from the
+	// tagged commit to publishing the release
+	MeanLeadTimeHours   float64 `json:"mean_lead_time_hours"`
+	MedianLeadTimeHours float64 `json:"median_lead_time_hours"`
+	// Trend compares the releases of the last 90 days with the 90 days
+	// before: accelerating, steady, slowing or insufficient_data
+	Trend string `json:"trend"`
+}
+
+// MonthlyCount is the number of releases published in a calendar month
+type MonthlyCount struct {
+	Month    string `json:"month"`
+	Releases int    `json:"releases"`
+}
+
+// GapBucket counts the gaps between consecutive releases in a range
+type GapBucket struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// CadenceReport holds the release cadence of each requested repository and
+// of all of them combined
+type CadenceReport struct {
+	OrgName      string           `json:"org_name,omitempty"`
+	Overall      ReleaseCadence   `json:"overall"`
+	Repositories []ReleaseCadence `json:"repositories"`
+	Errors       []RepoError      `json:"errors,omitempty"`
+}
+
+// DownloadChannel is a distribution channel besides release assets, such
+// as a container image or npm package published to GitHub Packages
+type DownloadChannel struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	URL        string   `json:"url"`
+	Versions   int      `json:"versions"`
+	LatestTags []string `json:"latest_tags,omitempty"`
+	// Downloads is nil when GitHub does not report downloads for the
+	// package, which is the case for container images
+	Downloads *int `json:"downloads,omitempty"`
+}
+
+// DownloadBreakdown sums asset downloads by operating system, architecture
+// and package format. Assets no rule matched are counted as "unknown"
+type DownloadBreakdown struct {
+	ByOS     map[string]int `json:"by_os"`
+	ByArch   map[string]int `json:"by_arch"`
+	ByFormat map[string]int `json:"by_format"`
+}
+
+// DownloadTrends describes how downloads move over time rather than their
+// cumulative totals
+type DownloadTrends struct {
+	// Interval is the bucket size of the velocity series, daily or weekly
+	Interval string `json:"interval"`
+	// LatestRelease is the most recently created release and
+	// LatestReleaseShare the fraction of all downloads it accounts for
+	LatestRelease      string             `json:"latest_release"`
+	LatestReleaseShare float64            `json:"latest_release_share"`
+	Releases           []ReleaseVelocity  `json:"releases"`
+	Migrations         []VersionMigration `json:"migrations"`
+}
+
+// ReleaseVelocity is the download rate of a release and its assets
+type ReleaseVelocity struct {
+	TagName  string          `json:"tag_name"`
+	Velocity []VelocityPoint `json:"velocity"`
+	Assets   []AssetVelocity `json:"assets"`
+}
+
+// AssetVelocity is the download rate of a single release asset
+type AssetVelocity struct {
+	Name     string          `json:"name"`
+	Velocity []VelocityPoint `json:"velocity"`
+}
+
+// VelocityPoint counts the downloads of one interval starting at Start
+type VelocityPoint struct {
+	Start     time.Time `json:"start"`
+	Downloads int       `json:"downloads"`
+}
+
+// VersionMigration measures how quickly downloads moved from a release to
+// the one following it
+type VersionMigration struct {
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	ReleasedAt time.Time `json:"released_at"`
+	// OvertakenAt is the start of the first interval in which To was
+	// downloaded more often than From, if that has happened yet
+	OvertakenAt *time.Time `json:"overtaken_at,omitempty"`
+	// DaysToOvertake is the time from ReleasedAt to OvertakenAt
+	DaysToOvertake float64 `json:"days_to_overtake,omitempty"`
+	// CurrentShare is To's share of the downloads of both releases in the
+	// most recent interval
+	CurrentShare float64 `json:"current_share"`
 }
 
 type Config struct {
 	GithubToken string
+	// Host is the GitHub host the request targets, e.g. github.com or a
+	// GitHub Enterprise Server hostname. Empty means public GitHub.
+	Host string
 }
 
 type Contributor struct {
 	Login string `json:"login"`
+	// Contributions is the number of commits to the default branch
+	Contributions int `json:"contributions"`
+	// Type is "Anonymous" for commit emails not linked to a GitHub account,
+	// listed on request; Name and Email are only set for those
+	Type  string `json:"type"`
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
 }
 
 type OrganizationStats struct {
 	OrgName           string `json:"org_name"`
 	TotalRepos        int    `json:"total_repos"`
 	TotalContributors int    `json:"total_contributors"`
+	// Repositories breaks the totals down by repository, and Contributors
+	// ranks the unique contributors by the number of repositories touched
+	Repositories []RepoContributors `json:"repositories"`
+	Contributors []OrgContributor   `json:"contributors"`
+	// Bots summarizes bot activity whether or not bots are counted
+	Bots   BotSummary  `json:"bots"`
+	Errors []RepoError `json:"errors,omitempty"`
+}
+
+// RepoContributors summarizes the contributors of one repository of an
+// organization. Commits sums their contributions to the default branch
+type RepoContributors struct {
+	RepoName     string `json:"repo_name"`
+	Contributors int    `json:"contributors"`
+	Commits      int    `json:"commits"`
+	Stars        int    `json:"stars"`
+	Archived     bool   `json:"archived"`
+	Fork         bool   `json:"fork"`
+}
+
+// OrgContributor is a unique contributor across the repositories of an
+// organization. Login is the canonical identity, which for anonymous
+// contributors is a commit email or the name given in the mailmap
+type OrgContributor struct {
+	Login         string `json:"login"`
+	Repos         int    `json:"repos"`
+	Contributions int    `json:"contributions"`
+	Anonymous     bool   `json:"anonymous,omitempty"`
+	Bot           bool   `json:"bot,omitempty"`
+}
+
+// RepoError reports a repository that could not be fetched as part of a
+// response covering several repositories
+type RepoError struct {
+	RepoName string `json:"repo_name"`
+	Error    string `json:"error"`
 }
 
 type StarHistory struct {
 	RepoName string      `json:"repo_name"`
 	History  []StarPoint `json:"history"`
+	// Sampled is set when History holds evenly spaced samples of the curve
+	// rather than one point per star; aggregation and forecasts interpolate
+	// linearly between them
+	Sampled bool `json:"sampled,omitempty"`
+	// Interval and Series describe an aggregated history: one point per
+	// daily, weekly or monthly interval (or evenly spaced points if
+	// Interval is empty), holding cumulative or new stars
+	Interval string `json:"interval,omitempty"`
+	Series   string `json:"series,omitempty"`
+	// Forecast projects the history forward on request
+	Forecast *StarForecast `json:"forecast,omitempty"`
+	// Events marks notable points of the history on request
+	Events []StarEvent `json:"events,omitempty"`
+}
+
+// StarEvent is a notable moment in a star history: a milestone crossed, a
+// spike of new stars or stars lost
+type StarEvent struct {
+	Type string    `json:"type"`
+	Date time.Time `json:"date"`
+	// Stars is the milestone reached, the stars gained during a spike or
+	// the number of stars lost
+	Stars int `json:"stars"`
+	// Days and Baseline describe a spike: how many days it lasted and the
+	// usual number of new stars per day before it
+	Days        int     `json:"days,omitempty"`
+	Baseline    float64 `json:"baseline,omitempty"`
+	Description string  `json:"description"`
+}
+
+// StarForecast is a trend fitted to the trailing window of a star history
+// and projected forward with a 95% prediction interval
+type StarForecast struct {
+	Model      string  `json:"model"`
+	WindowDays int     `json:"window_days"`
+	RSquared   float64 `json:"r_squared"`
+	// Capacity is the star count a logistic trend saturates at
+	Capacity   float64         `json:"capacity,omitempty"`
+	Projection []ForecastPoint `json:"projection"`
+	Milestones []StarMilestone `json:"milestones"`
+	// Error explains why no trend could be fitted, e.g. too little history
+	Error string `json:"error,omitempty"`
+}
+
+// ForecastPoint is a projected star count with its prediction interval
+type ForecastPoint struct {
+	Date  time.Time `json:"date"`
+	Stars int       `json:"stars"`
+	Lower int       `json:"lower"`
+	Upper int       `json:"upper"`
+}
+
+// StarMilestone is when a repository reached or is expected to reach a
+// star count. Earliest and Latest bound the expected date by the
+// prediction interval; nil dates lie beyond the projection limit
+type StarMilestone struct {
+	Stars    int        `json:"stars"`
+	Reached  bool       `json:"reached"`
+	Date     *time.Time `json:"date,omitempty"`
+	Earliest *time.Time `json:"earliest,omitempty"`
+	Latest   *time.Time `json:"latest,omitempty"`
 }
 
 // StarPoint represents stars at a specific point in time
@@ -65,6 +350,58 @@ type StarPoint struct {
 // MultiRepoStarHistory represents star history for multiple repositories
 type MultiRepoStarHistory struct {
 	Repositories []StarHistory `json:"repositories"`
+	// Aligned rebases every history to days since a common origin on
+	// request, so projects of different ages can be compared
+	Aligned []AlignedStarHistory `json:"aligned,omitempty"`
+	Errors  []RepoError          `json:"errors,omitempty"`
+}
+
+// AlignedStarHistory is a star history measured in days since its origin:
+// the first star or the creation of the repository
+type AlignedStarHistory struct {
+	RepoName     string             `json:"repo_name"`
+	Align        string             `json:"align"`
+	Origin       time.Time          `json:"origin"`
+	CurrentStars int                `json:"current_stars"`
+	Points       []AlignedStarPoint `json:"points"`
+}
+
+// AlignedStarPoint represents stars a number of days after the origin.
+// Percent is the share of the current stars, set when normalizing.
+type AlignedStarPoint struct {
+	Day     int      `json:"day"`
+	Stars   int      `json:"stars"`
+	Percent *float64 `json:"percent,omitempty"`
+}
+
+// ActivityHistory is the momentum of a repository over time: one series
+// per metric, all bucketed on the same intervals
+type ActivityHistory struct {
+	RepoName string           `json:"repo_name"`
+	Interval string           `json:"interval"`
+	Series   string           `json:"series"`
+	Metrics  []ActivitySeries `json:"metrics"`
+}
+
+// ActivitySeries is the series of one metric of an ActivityHistory, such
+// as forks or pull requests merged. Total counts every event recorded.
+type ActivitySeries struct {
+	Metric string          `json:"metric"`
+	Total  int             `json:"total"`
+	Points []ActivityPoint `json:"points"`
+}
+
+// ActivityPoint is the value of a metric for the interval starting at Date
+type ActivityPoint struct {
+	Date  time.Time `json:"date"`
+	Count int       `json:"count"`
+}
+
+// MultiRepoActivity represents the activity history of several
+// repositories
+type MultiRepoActivity struct {
+	Repositories []ActivityHistory `json:"repositories"`
+	Errors       []RepoError       `json:"errors,omitempty"`
 }
 
 // ActiveContributor represents a contributor's activity stats
@@ -72,6 +409,10 @@ type ActiveContributor struct {
 	Login          string    `json:"login"`
 	Contributions  int       `json:"contributions"`
 	LastActiveDate time.Time `json:"last_active_date"`
+	// Anonymous marks commits whose author has no GitHub login, included
+	// on request
+	Anonymous bool `json:"anonymous,omitempty"`
+	Bot       bool `json:"bot,omitempty"`
 }
 
 // ActiveContributorsResponse represents the response for active contributors
@@ -79,6 +420,24 @@ type ActiveContributorsResponse struct {
 	RepoName           string              `json:"repo_name"`
 	TimeRange          string              `json:"time_range"`
 	ActiveContributors []ActiveContributor `json:"active_contributors"`
+	// Bots summarizes bot activity whether or not bots are listed
+	Bots   BotSummary  `json:"bots"`
+	Errors []RepoError `json:"errors,omitempty"`
+}
+
+// BotSummary sums the contributions of bot accounts, busiest first
+type BotSummary struct {
+	Accounts      int           `json:"accounts"`
+	Contributions int           `json:"contributions"`
+	Bots          []BotActivity `json:"bots"`
+}
+
+// BotActivity is the activity of one bot account. Repos is only set for
+// organizations
+type BotActivity struct {
+	Login         string `json:"login"`
+	Contributions int    `json:"contributions"`
+	Repos         int    `json:"repos,omitempty"`
 }
 
 type StargazerResponse struct {
@@ -88,6 +447,7 @@ type StargazerResponse struct {
 
 type User struct {
 	Login     string `json:"login"`
+	Type      string `json:"type,omitempty"`
 	AvatarURL string `json:"avatar_url"`
 	Name      string `json:"name"`
 	Location  string `json:"location"`
@@ -112,3 +472,101 @@ type PageData struct {
 	NextPage   int
 	TotalCount int
 }
+
+// Repository represents the subset of a GitHub repository gitstats reads
+type Repository struct {
+	Name            string    `json:"name"`
+	FullName        string    `json:"full_name"`
+	StargazersCount int       `json:"stargazers_count"`
+	Archived        bool      `json:"archived"`
+	Fork            bool      `json:"fork"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// Issue represents the subset of an issue or pull request gitstats reads.
+// The issues endpoint lists both; PullRequest is only set for the latter.
+type Issue struct {
+	Number      int               `json:"number"`
+	State       string            `json:"state"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ClosedAt    *time.Time        `json:"closed_at"`
+	PullRequest *IssuePullRequest `json:"pull_request,omitempty"`
+}
+
+// IssuePullRequest marks an Issue as a pull request. MergedAt is nil for
+// pull requests closed without merging.
+type IssuePullRequest struct {
+	MergedAt *time.Time `json:"merged_at"`
+}
+
+// Commit represents a single entry of the commits list endpoint
+type Commit struct {
+	Author struct {
+		Login string `json:"login"`
+		Type  string `json:"type"`
+	} `json:"author"`
+	Commit struct {
+		Author struct {
+			Name  string    `json:"name"`
+			Email string    `json:"email"`
+			Date  time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+// Snapshot is the state of a repository's metrics at one point in time
+type Snapshot struct {
+	RepoName     string         `json:"repo_name"`
+	Time         time.Time      `json:"time"`
+	Stars        int            `json:"stars"`
+	Contributors int            `json:"contributors"`
+	Downloads    *DownloadStats `json:"downloads,omitempty"`
+}
+
+// MetricPoint is one snapshot reduced to the numbers charted over time
+type MetricPoint struct {
+	Time             time.Time      `json:"time"`
+	Stars            int            `json:"stars"`
+	Contributors     int            `json:"contributors"`
+	TotalDownloads   int            `json:"total_downloads"`
+	ReleaseDownloads map[string]int `json:"release_downloads,omitempty"`
+}
+
+// RepoHistory is the time series of stored snapshots of a repository
+type RepoHistory struct {
+	RepoName string        `json:"repo_name"`
+	Points   []MetricPoint `json:"points"`
+}
+
+// TrackedTarget is a repository or organization gitstats refreshes in the
+// background. Exactly one of Repo and Org is set
+type TrackedTarget struct {
+	// Repo is a repository URL
+	Repo string `json:"repo,omitempty"`
+	// Org is an organization name, on Host if that is set
+	Org  string `json:"org,omitempty"`
+	Host string `json:"host,omitempty"`
+}
+
+// Package is a package published to GitHub Packages, such as a container
+// image on ghcr.io
+type Package struct {
+	Name         string      `json:"name"`
+	PackageType  string      `json:"package_type"`
+	VersionCount int         `json:"version_count"`
+	HTMLURL      string      `json:"html_url"`
+	Owner        User        `json:"owner"`
+	Repository   *Repository `json:"repository"`
+}
+
+// PackageVersion is a published version of a Package. Container versions
+// are image digests carrying any number of tags
+type PackageVersion struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Metadata  struct {
+		Container struct {
+			Tags []string `json:"tags"`
+		} `json:"container"`
+	} `json:"metadata"`
+}
@@ -0,0 +1,210 @@
+// Package forecast fits simple growth models to a time series and projects
+// it forward with prediction intervals.
+//
+// Every model is an ordinary least squares line in a transformed space:
+// linear fits y directly, exponential fits ln(y), and logistic fits
+// ln(K/y - 1) for the capacity K that best explains the data. Projections
+// and their bands are computed on the line and transformed back, which
+// keeps the bands asymmetric where growth compounds.
+package forecast
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Supported models
+const (
+	Linear      = "linear"
+	Exponential = "exponential"
+	Logistic    = "logistic"
+)
+
+// z is the normal quantile of the two-sided 95% prediction interval.
+const z = 1.96
+
+// ErrTooFewPoints is returned when there is not enough variation in the
+// data to fit a model.
+var ErrTooFewPoints = errors.New("at least three points at different times are needed to fit a trend")
+
+// Model is a fitted trend.
+type Model struct {
+	kind string
+	// capacity is the saturation level K of a logistic model
+	capacity float64
+
+	// intercept and slope of the line in the transformed space, with what
+	// the prediction interval needs to know about the fit
+	intercept, slope float64
+	n                int
+	meanX, sxx       float64
+	sigma            float64
+
+	rSquared float64
+}
+
+// Fit fits the named model to the points (xs[i], ys[i]).
+func Fit(kind string, xs, ys []float64) (*Model, error) {
+	if len(xs) != len(ys) {
+		return nil, fmt.Errorf("got %d x values for %d y values", len(xs), len(ys))
+	}
+
+	switch kind {
+	case Linear:
+		return fitTransformed(kind, 0, xs, ys)
+	case Exponential:
+		for _, y := range ys {
+			if y <= 0 {
+				return nil, fmt.Errorf("an exponential trend needs positive values")
+			}
+		}
+		return fitTransformed(kind, 0, xs, ys)
+	case Logistic:
+		return fitLogistic(xs, ys)
+	default:
+		return nil, fmt.Errorf("unknown model %q, expected linear, exponential or logistic", kind)
+	}
+}
+
+// fitLogistic searches the capacity K on a logarithmic grid above the
+// largest observation and keeps the fit with the smallest squared error.
+func fitLogistic(xs, ys []float64) (*Model, error) {
+	maxY := 0.0
+	for _, y := range ys {
+		if y <= 0 {
+			return nil, fmt.Errorf("a logistic trend needs positive values")
+		}
+		maxY = max(maxY, y)
+	}
+
+	var best *Model
+	bestSSE := math.Inf(1)
+	for i := 1; i <= 200; i++ {
+		// From 1% to 100x above the current maximum
+		capacity := maxY * math.Pow(100, float64(i)/200) * 1.01
+		m, err := fitTransformed(Logistic, capacity, xs, ys)
+		if err != nil {
+			return nil, err
+		}
+		if sse := m.sse(xs, ys); sse < bestSSE {
+			best, bestSSE = m, sse
+		}
+	}
+	return best, nil
+}
+
+func fitTransformed(kind string, capacity float64, xs, ys []float64) (*Model, error) {
+	m := &Model{kind: kind, capacity: capacity, n: len(xs)}
+	if m.n < 3 {
+		return nil, ErrTooFewPoints
+	}
+
+	us := make([]float64, len(ys))
+	for i, y := range ys {
+		us[i] = m.transform(y)
+	}
+
+	meanU := 0.0
+	for i := range xs {
+		m.meanX += xs[i]
+		meanU += us[i]
+	}
+	m.meanX /= float64(m.n)
+	meanU /= float64(m.n)
+
+	sxu := 0.0
+	for i := range xs {
+		m.sxx += (xs[i] - m.meanX) * (xs[i] - m.meanX)
+		sxu += (xs[i] - m.meanX) * (us[i] - meanU)
+	}
+	if m.sxx == 0 {
+		return nil, ErrTooFewPoints
+	}
+	m.slope = sxu / m.sxx
+	m.intercept = meanU - m.slope*m.meanX
+
+	residuals := 0.0
+	for i := range xs {
+		r := us[i] - m.line(xs[i])
+		residuals += r * r
+	}
+	m.sigma = math.Sqrt(residuals / float64(m.n-2))
+
+	meanY, sst := 0.0, 0.0
+	for _, y := range ys {
+		meanY += y
+	}
+	meanY /= float64(m.n)
+	for _, y := range ys {
+		sst += (y - meanY) * (y - meanY)
+	}
+	if sst > 0 {
+		m.rSquared = 1 - m.sse(xs, ys)/sst
+	}
+	return m, nil
+}
+
+func (m *Model) line(x float64) float64 {
+	return m.intercept + m.slope*x
+}
+
+func (m *Model) transform(y float64) float64 {
+	switch m.kind {
+	case Exponential:
+		return math.Log(y)
+	case Logistic:
+		return math.Log(m.capacity/y - 1)
+	}
+	return y
+}
+
+func (m *Model) inverse(u float64) float64 {
+	switch m.kind {
+	case Exponential:
+		return math.Exp(u)
+	case Logistic:
+		return m.capacity / (1 + math.Exp(u))
+	}
+	return u
+}
+
+func (m *Model) sse(xs, ys []float64) float64 {
+	sse := 0.0
+	for i := range xs {
+		r := ys[i] - m.inverse(m.line(xs[i]))
+		sse += r * r
+	}
+	return sse
+}
+
+// Kind returns the name of the model.
+func (m *Model) Kind() string {
+	return m.kind
+}
+
+// Capacity returns the saturation level of a logistic model, zero for the
+// other models.
+func (m *Model) Capacity() float64 {
+	return m.capacity
+}
+
+// RSquared returns the share of the variance of the observations the model
+// explains.
+func (m *Model) RSquared() float64 {
+	return m.rSquared
+}
+
+// Predict returns the projected value at x and its 95% prediction
+// interval.
+func (m *Model) Predict(x float64) (y, lower, upper float64) {
+	u := m.line(x)
+	half := z * m.sigma * math.Sqrt(1+1/float64(m.n)+(x-m.meanX)*(x-m.meanX)/m.sxx)
+
+	y, lower, upper = m.inverse(u), m.inverse(u-half), m.inverse(u+half)
+	if lower > upper {
+		// The logistic transform is decreasing
+		lower, upper = upper, lower
+	}
+	return y, lower, upper
+}
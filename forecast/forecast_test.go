@@ -0,0 +1,85 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+)
+
+func series(n int, f func(x float64) float64) ([]float64, []float64) {
+	xs, ys := make([]float64, n), make([]float64, n)
+	for i := range xs {
+		xs[i] = float64(i)
+		ys[i] = f(xs[i])
+	}
+	return xs, ys
+}
+
+func TestFitRecoversTrend(t *testing.T) {
+	tests := []struct {
+		kind string
+		f    func(x float64) float64
+	}{
+		{Linear, func(x float64) float64 { return 100 + 5*x + 3*math.Sin(x) }},
+		{Exponential, func(x float64) float64 { return 50 * math.Exp(0.02*x) * (1 + 0.01*math.Sin(x)) }},
+		{Logistic, func(x float64) float64 { return 5000 / (1 + math.Exp(-0.05*(x-60))) }},
+	}
+
+	for _, tt := range tests {
+		xs, ys := series(90, tt.f)
+		m, err := Fit(tt.kind, xs, ys)
+		if err != nil {
+			t.Fatalf("Fit(%s) returned error: %v", tt.kind, err)
+		}
+		if m.RSquared() < 0.99 {
+			t.Errorf("Expected %s fit with R² above 0.99, got %v", tt.kind, m.RSquared())
+		}
+
+		y, lower, upper := m.Predict(120)
+		want := tt.f(120)
+		if math.Abs(y-want)/want > 0.05 {
+			t.Errorf("Expected %s projection near %v, got %v", tt.kind, want, y)
+		}
+		if !(lower <= y && y <= upper) {
+			t.Errorf("Expected %s projection %v within [%v, %v]", tt.kind, y, lower, upper)
+		}
+	}
+}
+
+func TestFitLogisticCapacity(t *testing.T) {
+	xs, ys := series(150, func(x float64) float64 { return 2000 / (1 + math.Exp(-0.08*(x-50))) })
+	m, err := Fit(Logistic, xs, ys)
+	if err != nil {
+		t.Fatalf("Fit returned error: %v", err)
+	}
+	if math.Abs(m.Capacity()-2000)/2000 > 0.05 {
+		t.Errorf("Expected capacity near 2000, got %v", m.Capacity())
+	}
+}
+
+func TestBandsWidenWithDistance(t *testing.T) {
+	xs, ys := series(30, func(x float64) float64 { return 10*x + 5*math.Cos(3*x) })
+	m, err := Fit(Linear, xs, ys)
+	if err != nil {
+		t.Fatalf("Fit returned error: %v", err)
+	}
+	_, nearLow, nearHigh := m.Predict(31)
+	_, farLow, farHigh := m.Predict(300)
+	if farHigh-farLow <= nearHigh-nearLow {
+		t.Errorf("Expected wider bands further out: near %v, far %v", nearHigh-nearLow, farHigh-farLow)
+	}
+}
+
+func TestFitErrors(t *testing.T) {
+	if _, err := Fit(Linear, []float64{1, 2}, []float64{1, 2}); err != ErrTooFewPoints {
+		t.Errorf("Expected ErrTooFewPoints, got %v", err)
+	}
+	if _, err := Fit(Linear, []float64{1, 1, 1}, []float64{1, 2, 3}); err != ErrTooFewPoints {
+		t.Errorf("Expected ErrTooFewPoints for identical x values, got %v", err)
+	}
+	if _, err := Fit(Exponential, []float64{0, 1, 2}, []float64{0, 1, 2}); err == nil {
+		t.Errorf("Expected error for non-positive values")
+	}
+	if _, err := Fit("quadratic", []float64{0, 1, 2}, []float64{0, 1, 2}); err == nil {
+		t.Errorf("Expected error for an unknown model")
+	}
+}
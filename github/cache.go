@@ -0,0 +1,161 @@
+package github
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CachedResponse is a GitHub response kept around to answer conditional
+// requests. GitHub does not count 304 responses against the rate limit, so
+// replaying a cached body after a 304 is effectively free.
+type CachedResponse struct {
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+}
+
+// Cache stores responses by an opaque key derived from the request URL, the
+// media type and the credentials used.
+type Cache interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, response *CachedResponse)
+}
+
+// WithCache enables conditional requests backed by cache.
+func WithCache(cache Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// cacheKey separates entries by token so a response fetched with one
+// user's credentials is never replayed to another.
+func (c *Client) cacheKey(u, accept string) string {
+	tokenHash := sha256.Sum256([]byte(c.token))
+	return hex.EncodeToString(tokenHash[:8]) + " " + accept + " " + u
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used
+// entry once it holds more than its capacity.
+type MemoryCache struct {
+	capacity int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key      string
+	response *CachedResponse
+}
+
+// NewMemoryCache returns a MemoryCache holding at most capacity responses.
+func NewMemoryCache(capacity int) *MemoryCache {
+	return &MemoryCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (m *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	m.order.MoveToFront(elem)
+	return elem.Value.(*memoryEntry).response, true
+}
+
+func (m *MemoryCache) Set(key string, response *CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.entries[key]; ok {
+		elem.Value.(*memoryEntry).response = response
+		m.order.MoveToFront(elem)
+		return
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryEntry{key: key, response: response})
+	for m.capacity > 0 && m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+// Len returns the number of cached responses.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// DiskCache is a Cache that keeps one JSON file per response in a
+// directory, so cached responses survive restarts.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a DiskCache rooted at dir, creating it if needed.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (d *DiskCache) Get(key string) (*CachedResponse, bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var response CachedResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, false
+	}
+	return &response, true
+}
+
+// Set writes the entry to a temporary file first so concurrent readers
+// never observe a partially written response. Write failures only cost a
+// cache miss and are ignored.
+func (d *DiskCache) Set(key string, response *CachedResponse) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(d.dir, "tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	os.Rename(tmp.Name(), d.path(key))
+}
@@ -0,0 +1,109 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConditionalRequestServedFromCache(t *testing.T) {
+	caches := map[string]func(t *testing.T) Cache{
+		"memory": func(t *testing.T) Cache { return NewMemoryCache(10) },
+		"disk": func(t *testing.T) Cache {
+			cache, err := NewDiskCache(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewDiskCache returned error: %v", err)
+			}
+			return cache
+		},
+	}
+
+	for name, newCache := range caches {
+		t.Run(name, func(t *testing.T) {
+			calls, notModified := 0, 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					notModified++
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Header().Set("ETag", `"v1"`)
+				w.Write([]byte(`{"login":"octocat","name":"The Octocat"}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(WithBaseURL(server.URL), WithCache(newCache(t)))
+			for i := 0; i < 3; i++ {
+				user, err := client.GetUser(context.Background(), "octocat")
+				if err != nil {
+					t.Fatalf("GetUser returned error: %v", err)
+				}
+				if user.Name != "The Octocat" {
+					t.Errorf("Expected cached name, got %q", user.Name)
+				}
+			}
+			if calls != 3 || notModified != 2 {
+				t.Errorf("Expected 3 calls with 2 revalidations, got %d calls and %d revalidations", calls, notModified)
+			}
+		})
+	}
+}
+
+func TestCachedPagesKeepLinkHeader(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		etag := fmt.Sprintf(`"page-%s"`, page)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		if page == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=2>; rel="next"`, server.URL, r.URL.Path))
+			w.Write([]byte(`[{"login":"a"}]`))
+			return
+		}
+		w.Write([]byte(`[{"login":"b"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithCache(NewMemoryCache(10)))
+	for i := 0; i < 2; i++ {
+		contributors, err := client.ListContributors(context.Background(), "owner", "repo", false)
+		if err != nil {
+			t.Fatalf("ListContributors returned error: %v", err)
+		}
+		if len(contributors) != 2 {
+			t.Errorf("Run %d: expected 2 contributors, got %d", i, len(contributors))
+		}
+	}
+}
+
+func TestCacheSeparatesTokens(t *testing.T) {
+	client := NewClient()
+	if client.WithToken("a").cacheKey("u", "") == client.WithToken("b").cacheKey("u", "") {
+		t.Errorf("Expected different cache keys for different tokens")
+	}
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set("a", &CachedResponse{ETag: "a"})
+	cache.Set("b", &CachedResponse{ETag: "b"})
+	cache.Get("a")
+	cache.Set("c", &CachedResponse{ETag: "c"})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("Expected b to be evicted")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Errorf("Expected a to be kept")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", cache.Len())
+	}
+}
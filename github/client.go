@@ -0,0 +1,277 @@
+// Package github implements the small subset of the GitHub REST API that
+// gitstats needs, behind a single configurable client.
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the API root of public GitHub.
+	DefaultBaseURL = "https://api.github.com/"
+	// DefaultUserAgent is sent with every request unless overridden.
+	DefaultUserAgent = "gitstats"
+	// DefaultTimeout bounds a single HTTP round trip.
+	DefaultTimeout = 30 * time.Second
+
+	mediaTypeV3   = "application/vnd.github.v3+json"
+	mediaTypeStar = "application/vnd.github.v3.star+json"
+
+	defaultPerPage = 100
+)
+
+// Client talks to a GitHub API endpoint. A Client is safe for concurrent use;
+// per-request credentials are attached with WithToken, which returns a copy
+// sharing the underlying transport.
+type Client struct {
+	baseURL    string
+	userAgent  string
+	token      string
+	httpClient *http.Client
+	graphqlURL string
+	scheduler  *scheduler
+	cache      Cache
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL points the client at a different API root, e.g. a test server.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if !strings.HasSuffix(baseURL, "/") {
+			baseURL += "/"
+		}
+		c.baseURL = baseURL
+	}
+}
+
+// WithUserAgent overrides the User-Agent header.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithTimeout sets the timeout of the underlying http.Client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithTransport sets the RoundTripper of the underlying http.Client.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = transport
+	}
+}
+
+// WithHTTPClient replaces the underlying http.Client entirely.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewClient returns a Client for public GitHub, adjusted by opts.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		userAgent:  DefaultUserAgent,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		scheduler:  newScheduler(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithToken returns a copy of c that authenticates with token. An empty
+// token yields an unauthenticated copy.
+func (c *Client) WithToken(token string) *Client {
+	clone := *c
+	clone.token = token
+	return &clone
+}
+
+// BaseURL returns the API root the client talks to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// ErrorResponse is returned for any non-successful GitHub response.
+type ErrorResponse struct {
+	StatusCode int
+	URL        string
+	Body       string
+}
+
+func (e *ErrorResponse) Error() string {
+	return fmt.Sprintf("GitHub API returned status: %d, body: %s", e.StatusCode, e.Body)
+}
+
+// url resolves path and query against the client's base URL.
+func (c *Client) url(path string, query url.Values) string {
+	u := c.baseURL + strings.TrimPrefix(path, "/")
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+func (c *Client) newRequest(ctx context.Context, method, u, accept string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	if accept == "" {
+		accept = mediaTypeV3
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return req, nil
+}
+
+// do sends req and returns the response if GitHub answered with a 2xx or
+// 304 status. The caller owns the response body.
+//
+// Requests are held back while the token's budget is known to be exhausted,
+// and retried after the advertised pause when GitHub answers with a primary
+// or secondary rate limit, as long as that pause fits the client's maximum
+// wait.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	budget := budgetKey(c.token, req)
+	for attempt := 0; ; attempt++ {
+		if err := c.scheduler.wait(ctx, budget); err != nil {
+			return nil, err
+		}
+
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("error creating request: %v", err)
+			}
+			req.Body = body
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error making request: %w", err)
+		}
+		c.scheduler.observe(budget, resp.Header)
+
+		err = checkResponse(resp, attempt)
+		if err == nil {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		pause, retry := c.scheduler.backoff(err, attempt)
+		if !retry {
+			return nil, err
+		}
+		if err := c.scheduler.sleep(ctx, pause); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func checkResponse(resp *http.Response, attempt int) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 || resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if err := rateLimitError(resp, string(body), attempt); err != nil {
+		return err
+	}
+
+	return &ErrorResponse{
+		StatusCode: resp.StatusCode,
+		URL:        resp.Request.URL.String(),
+		Body:       string(body),
+	}
+}
+
+// get fetches path and decodes the JSON body into v. A 204 response leaves v
+// untouched.
+func (c *Client) get(ctx context.Context, path string, query url.Values, accept string, v any) (*http.Response, error) {
+	return c.getURL(ctx, c.url(path, query), accept, v)
+}
+
+// getURL is get for an already resolved URL, such as a Link header target.
+//
+// With a cache configured, the request carries the validators of the last
+// response for the same URL, and a 304 answer is served from the cache.
+func (c *Client) getURL(ctx context.Context, u, accept string, v any) (*http.Response, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, u, accept, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var key string
+	var cached *CachedResponse
+	if c.cache != nil {
+		key = c.cacheKey(u, accept)
+		if entry, ok := c.cache.Get(key); ok {
+			cached = entry
+			if entry.ETag != "" {
+				req.Header.Set("If-None-Match", entry.ETag)
+			}
+			if entry.LastModified != "" {
+				req.Header.Set("If-Modified-Since", entry.LastModified)
+			}
+		}
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body []byte
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		resp.StatusCode = http.StatusOK
+		resp.Header = cached.Header.Clone()
+		body = cached.Body
+	case resp.StatusCode == http.StatusNotModified:
+		return nil, &ErrorResponse{StatusCode: resp.StatusCode, URL: u}
+	case resp.StatusCode == http.StatusNoContent:
+		return resp, nil
+	default:
+		body, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return resp, fmt.Errorf("error reading response: %w", err)
+		}
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if c.cache != nil && (etag != "" || lastModified != "") {
+			c.cache.Set(key, &CachedResponse{
+				ETag:         etag,
+				LastModified: lastModified,
+				Header:       resp.Header.Clone(),
+				Body:         body,
+			})
+		}
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return resp, fmt.Errorf("error decoding response: %v", err)
+	}
+	return resp, nil
+}
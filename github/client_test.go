@@ -0,0 +1,112 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientSendsHeaders(t *testing.T) {
+	var gotAuth, gotAgent, gotAccept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotAgent = r.Header.Get("User-Agent")
+		gotAccept = r.Header.Get("Accept")
+		w.Write([]byte(`{"login":"octocat"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithUserAgent("gitstats-test")).WithToken("secret")
+	user, err := client.GetUser(context.Background(), "octocat")
+	if err != nil {
+		t.Fatalf("GetUser returned error: %v", err)
+	}
+	if user.Login != "octocat" {
+		t.Errorf("Expected login octocat, got %q", user.Login)
+	}
+	if gotAuth != "Bearer secret" {
+		t.Errorf("Expected Authorization %q, got %q", "Bearer secret", gotAuth)
+	}
+	if gotAgent != "gitstats-test" {
+		t.Errorf("Expected User-Agent %q, got %q", "gitstats-test", gotAgent)
+	}
+	if gotAccept != mediaTypeV3 {
+		t.Errorf("Expected Accept %q, got %q", mediaTypeV3, gotAccept)
+	}
+}
+
+func TestClientWithoutTokenOmitsAuthorization(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Expected no Authorization header, got %q", auth)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	if _, err := NewClient(WithBaseURL(server.URL)).GetUser(context.Background(), "octocat"); err != nil {
+		t.Fatalf("GetUser returned error: %v", err)
+	}
+}
+
+func TestListContributorsNoContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	contributors, err := NewClient(WithBaseURL(server.URL)).ListContributors(context.Background(), "owner", "empty", false)
+	if err != nil {
+		t.Fatalf("ListContributors returned error: %v", err)
+	}
+	if len(contributors) != 0 {
+		t.Errorf("Expected no contributors, got %d", len(contributors))
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		headers map[string]string
+		check   func(error) bool
+	}{
+		{
+			name:   "not found",
+			status: http.StatusNotFound,
+			check: func(err error) bool {
+				var errResp *ErrorResponse
+				return errors.As(err, &errResp) && errResp.StatusCode == http.StatusNotFound
+			},
+		},
+		{
+			name:    "rate limited",
+			status:  http.StatusForbidden,
+			headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Limit": "60", "X-RateLimit-Reset": "4102444800"},
+			check: func(err error) bool {
+				var rateErr *RateLimitError
+				return errors.As(err, &rateErr) && rateErr.Rate.Limit == 60
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.headers {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"message":"nope"}`))
+			}))
+			defer server.Close()
+
+			_, err := NewClient(WithBaseURL(server.URL)).GetRepository(context.Background(), "owner", "repo")
+			if err == nil || !tt.check(err) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
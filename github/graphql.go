@@ -0,0 +1,148 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrGraphQLAuth is returned for GraphQL calls on an unauthenticated client;
+// unlike REST, the GraphQL API rejects anonymous requests.
+var ErrGraphQLAuth = errors.New("the GitHub GraphQL API requires a token")
+
+// GraphQLError collects the errors GitHub reported for a GraphQL query.
+type GraphQLError struct {
+	Messages []string
+}
+
+func (e *GraphQLError) Error() string {
+	return "GitHub GraphQL error: " + strings.Join(e.Messages, "; ")
+}
+
+// WithGraphQLURL overrides the GraphQL endpoint. By default it is derived
+// from the REST base URL: https://api.github.com/graphql for public GitHub
+// and https://<host>/api/graphql for GitHub Enterprise Server.
+func WithGraphQLURL(graphqlURL string) Option {
+	return func(c *Client) {
+		c.graphqlURL = graphqlURL
+	}
+}
+
+// GraphQLURL returns the GraphQL endpoint the client talks to.
+func (c *Client) GraphQLURL() string {
+	if c.graphqlURL != "" {
+		return c.graphqlURL
+	}
+	if base, ok := strings.CutSuffix(c.baseURL, "/api/v3/"); ok {
+		return base + "/api/graphql"
+	}
+	return c.baseURL + "graphql"
+}
+
+// graphql runs query with variables and decodes the "data" member of the
+// response into v.
+func (c *Client) graphql(ctx context.Context, query string, variables map[string]any, v any) error {
+	if c.token == "" {
+		return ErrGraphQLAuth
+	}
+
+	payload, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return fmt.Errorf("error encoding query: %v", err)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, c.GraphQLURL(), "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("error decoding response: %v", err)
+	}
+
+	if len(result.Errors) > 0 {
+		gqlErr := &GraphQLError{}
+		for _, e := range result.Errors {
+			gqlErr.Messages = append(gqlErr.Messages, e.Message)
+		}
+		return gqlErr
+	}
+	if err := json.Unmarshal(result.Data, v); err != nil {
+		return fmt.Errorf("error decoding response: %v", err)
+	}
+	return nil
+}
+
+const starredAtQuery = `query($owner: String!, $name: String!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    stargazers(first: 100, after: $cursor, orderBy: {field: STARRED_AT, direction: ASC}) {
+      pageInfo { endCursor hasNextPage }
+      edges { starredAt }
+    }
+  }
+}`
+
+// StarTimes iterates over the time of every star of a repository, oldest
+// first, using the GraphQL API. Cursor pagination is not subject to the
+// 400 page limit of the REST stargazers endpoint.
+func (c *Client) StarTimes(ctx context.Context, owner, repo string) iter.Seq2[time.Time, error] {
+	return func(yield func(time.Time, error) bool) {
+		var cursor *string
+		for {
+			var data struct {
+				Repository *struct {
+					Stargazers struct {
+						PageInfo struct {
+							EndCursor   string `json:"endCursor"`
+							HasNextPage bool   `json:"hasNextPage"`
+						} `json:"pageInfo"`
+						Edges []struct {
+							StarredAt time.Time `json:"starredAt"`
+						} `json:"edges"`
+					} `json:"stargazers"`
+				} `json:"repository"`
+			}
+
+			variables := map[string]any{"owner": owner, "name": repo, "cursor": cursor}
+			if err := c.graphql(ctx, starredAtQuery, variables, &data); err != nil {
+				yield(time.Time{}, err)
+				return
+			}
+			if data.Repository == nil {
+				yield(time.Time{}, fmt.Errorf("repository %s/%s not found", owner, repo))
+				return
+			}
+
+			for _, edge := range data.Repository.Stargazers.Edges {
+				if !yield(edge.StarredAt, nil) {
+					return
+				}
+			}
+
+			pageInfo := data.Repository.Stargazers.PageInfo
+			if !pageInfo.HasNextPage {
+				return
+			}
+			cursor = &pageInfo.EndCursor
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStarTimesFollowsCursor(t *testing.T) {
+	var cursors []any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/graphql" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		cursors = append(cursors, body.Variables["cursor"])
+
+		if body.Variables["cursor"] == nil {
+			w.Write([]byte(`{"data":{"repository":{"stargazers":{"pageInfo":{"endCursor":"c1","hasNextPage":true},
+				"edges":[{"starredAt":"2020-01-01T00:00:00Z"},{"starredAt":"2020-01-02T00:00:00Z"}]}}}}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"repository":{"stargazers":{"pageInfo":{"endCursor":"c2","hasNextPage":false},
+			"edges":[{"starredAt":"2020-02-01T00:00:00Z"}]}}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL)).WithToken("secret")
+	times, err := Collect(client.StarTimes(context.Background(), "owner", "repo"))
+	if err != nil {
+		t.Fatalf("StarTimes returned error: %v", err)
+	}
+	if len(times) != 3 {
+		t.Errorf("Expected 3 star times, got %d", len(times))
+	}
+	if len(cursors) != 2 || cursors[0] != nil || cursors[1] != "c1" {
+		t.Errorf("Unexpected cursors %v", cursors)
+	}
+}
+
+func TestStarTimesErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"repository":null},"errors":[{"message":"Could not resolve to a Repository"}]}`))
+	}))
+	defer server.Close()
+
+	_, err := Collect(NewClient(WithBaseURL(server.URL)).StarTimes(context.Background(), "owner", "repo"))
+	if !errors.Is(err, ErrGraphQLAuth) {
+		t.Errorf("Expected ErrGraphQLAuth without token, got %v", err)
+	}
+
+	_, err = Collect(NewClient(WithBaseURL(server.URL)).WithToken("secret").StarTimes(context.Background(), "owner", "repo"))
+	var gqlErr *GraphQLError
+	if !errors.As(err, &gqlErr) || len(gqlErr.Messages) != 1 {
+		t.Errorf("Expected GraphQLError, got %v", err)
+	}
+}
+
+func TestGraphQLURL(t *testing.T) {
+	tests := []struct {
+		base string
+		want string
+	}{
+		{DefaultBaseURL, "https://api.github.com/graphql"},
+		{"https://ghe.example.com/api/v3", "https://ghe.example.com/api/graphql"},
+	}
+	for _, tt := range tests {
+		if got := NewClient(WithBaseURL(tt.base)).GraphQLURL(); got != tt.want {
+			t.Errorf("GraphQLURL() for %s = %s, want %s", tt.base, got, tt.want)
+		}
+	}
+}
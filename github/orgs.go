@@ -0,0 +1,38 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+// OrgRepos iterates over every repository of an organization. repoType is
+// passed through as the `type` filter (all, public, private, forks, ...);
+// an empty value uses GitHub's default.
+func (c *Client) OrgRepos(ctx context.Context, org, repoType string) iter.Seq2[cu.Repository, error] {
+	query := url.Values{}
+	if repoType != "" {
+		query.Set("type", repoType)
+	}
+	return paginate[cu.Repository](ctx, c, fmt.Sprintf("orgs/%s/repos", org), query, "")
+}
+
+// ListOrgRepos returns every repository of an organization.
+func (c *Client) ListOrgRepos(ctx context.Context, org, repoType string) ([]cu.Repository, error) {
+	return Collect(c.OrgRepos(ctx, org, repoType))
+}
+
+// OrgMembers iterates over the members of an organization visible to the
+// client's credentials.
+func (c *Client) OrgMembers(ctx context.Context, org string) iter.Seq2[cu.User, error] {
+	return paginate[cu.User](ctx, c, fmt.Sprintf("orgs/%s/members", org), nil, "")
+}
+
+// ListOrgMembers returns the members of an organization visible to the
+// client's credentials.
+func (c *Client) ListOrgMembers(ctx context.Context, org string) ([]cu.User, error) {
+	return Collect(c.OrgMembers(ctx, org))
+}
@@ -0,0 +1,79 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+// ListOwnerPackages returns the packages of packageType (container, npm,
+// maven, rubygems or nuget) published by owner, which may be an
+// organization or a user. GitHub requires a token with the read:packages
+// scope even for public packages.
+func (c *Client) ListOwnerPackages(ctx context.Context, owner, packageType string) ([]cu.Package, error) {
+	query := url.Values{}
+	query.Set("package_type", packageType)
+
+	packages, err := Collect(paginate[cu.Package](ctx, c, fmt.Sprintf("orgs/%s/packages", owner), query, ""))
+	var errResp *ErrorResponse
+	if errors.As(err, &errResp) && errResp.StatusCode == http.StatusNotFound {
+		return Collect(paginate[cu.Package](ctx, c, fmt.Sprintf("users/%s/packages", owner), query, ""))
+	}
+	return packages, err
+}
+
+// PackageVersions iterates over the versions of a package, newest first.
+func (c *Client) PackageVersions(ctx context.Context, pkg cu.Package) iter.Seq2[cu.PackageVersion, error] {
+	ownerPath := "users"
+	if pkg.Owner.Type == "Organization" {
+		ownerPath = "orgs"
+	}
+	path := fmt.Sprintf("%s/%s/packages/%s/%s/versions", ownerPath, pkg.Owner.Login, pkg.PackageType, url.PathEscape(pkg.Name))
+	return paginate[cu.PackageVersion](ctx, c, path, nil, "")
+}
+
+const packageDownloadsQuery = `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    packages(first: 100) {
+      nodes { name statistics { downloadsTotalCount } }
+    }
+  }
+}`
+
+// PackageDownloads returns the download counts GitHub reports for the
+// packages of a repository, by package name. Only the GraphQL API exposes
+// them, and not for every registry: container images are missing.
+func (c *Client) PackageDownloads(ctx context.Context, owner, repo string) (map[string]int, error) {
+	var data struct {
+		Repository *struct {
+			Packages struct {
+				Nodes []struct {
+					Name       string `json:"name"`
+					Statistics *struct {
+						DownloadsTotalCount int `json:"downloadsTotalCount"`
+					} `json:"statistics"`
+				} `json:"nodes"`
+			} `json:"packages"`
+		} `json:"repository"`
+	}
+	variables := map[string]any{"owner": owner, "name": repo}
+	if err := c.graphql(ctx, packageDownloadsQuery, variables, &data); err != nil {
+		return nil, err
+	}
+	if data.Repository == nil {
+		return nil, fmt.Errorf("repository %s/%s not found", owner, repo)
+	}
+
+	downloads := make(map[string]int)
+	for _, node := range data.Repository.Packages.Nodes {
+		if node.Statistics != nil {
+			downloads[node.Name] = node.Statistics.DownloadsTotalCount
+		}
+	}
+	return downloads, nil
+}
@@ -0,0 +1,53 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListOwnerPackagesFallsBackToUser(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if got := r.URL.Query().Get("package_type"); got != "container" {
+			t.Errorf("Expected package_type container, got %q", got)
+		}
+		if r.URL.Path == "/orgs/octocat/packages" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[{"name":"cli","package_type":"container","version_count":3,"owner":{"login":"octocat","type":"User"}}]`))
+	}))
+	defer server.Close()
+
+	packages, err := NewClient(WithBaseURL(server.URL)).ListOwnerPackages(context.Background(), "octocat", "container")
+	if err != nil {
+		t.Fatalf("ListOwnerPackages returned error: %v", err)
+	}
+	if len(packages) != 1 || packages[0].VersionCount != 3 {
+		t.Errorf("Unexpected packages %+v", packages)
+	}
+	if len(paths) != 2 || paths[1] != "/users/octocat/packages" {
+		t.Errorf("Expected a fallback to the user packages, got %v", paths)
+	}
+}
+
+func TestPackageDownloads(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"repository":{"packages":{"nodes":[
+			{"name":"sdk","statistics":{"downloadsTotalCount":42}},
+			{"name":"image","statistics":null}
+		]}}}}`))
+	}))
+	defer server.Close()
+
+	downloads, err := NewClient(WithBaseURL(server.URL)).WithToken("secret").PackageDownloads(context.Background(), "owner", "repo")
+	if err != nil {
+		t.Fatalf("PackageDownloads returned error: %v", err)
+	}
+	if len(downloads) != 1 || downloads["sdk"] != 42 {
+		t.Errorf("Unexpected downloads %v", downloads)
+	}
+}
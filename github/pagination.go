@@ -0,0 +1,89 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+	"strings"
+)
+
+// paginate returns an iterator over every item of a list endpoint. The first
+// page is requested with per_page=100; subsequent pages are whatever GitHub
+// advertises as rel="next" in the Link header, so iteration ends exactly when
+// GitHub says there is nothing left rather than on a short page.
+//
+// Pages are fetched lazily: breaking out of the loop stops further requests.
+// A request or decoding error is yielded once, after which iteration ends.
+func paginate[T any](ctx context.Context, c *Client, path string, query url.Values, accept string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		if q.Get("per_page") == "" {
+			q.Set("per_page", fmt.Sprint(defaultPerPage))
+		}
+
+		next := c.url(path, q)
+		for next != "" {
+			var items []T
+			resp, err := c.getURL(ctx, next, accept, &items)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			next = parseLinkHeader(resp.Header.Get("Link"))["next"]
+		}
+	}
+}
+
+// Collect drains seq into a slice, stopping at the first error.
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var all []T
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, item)
+	}
+	return all, nil
+}
+
+// parseLinkHeader maps each rel of an RFC 8288 Link header to its target,
+// e.g. `<https://api.github.com/...&page=2>; rel="next"` yields
+// {"next": "https://api.github.com/...&page=2"}.
+func parseLinkHeader(header string) map[string]string {
+	links := make(map[string]string)
+	for _, link := range strings.Split(header, ",") {
+		segments := strings.Split(link, ";")
+		if len(segments) < 2 {
+			continue
+		}
+
+		target := strings.TrimSpace(segments[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+
+		for _, param := range segments[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(key) != "rel" {
+				continue
+			}
+			for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+				links[rel] = target
+			}
+		}
+	}
+	return links
+}
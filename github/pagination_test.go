@@ -0,0 +1,108 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// pagedServer serves total items at path in pages of perPage, advertising
+// the following page via a Link header the way GitHub does.
+func pagedServer(t *testing.T, total, perPage int, requests *int) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		lastPage := (total + perPage - 1) / perPage
+		if page < lastPage {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=%d>; rel="next", <%s%s?page=%d>; rel="last"`,
+				server.URL, r.URL.Path, page+1, server.URL, r.URL.Path, lastPage))
+		}
+
+		w.Write([]byte("["))
+		for i := (page - 1) * perPage; i < page*perPage && i < total; i++ {
+			if i > (page-1)*perPage {
+				w.Write([]byte(","))
+			}
+			fmt.Fprintf(w, `{"login":"user%d"}`, i)
+		}
+		w.Write([]byte("]"))
+	}))
+	return server
+}
+
+func TestPaginateFollowsLinkHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int
+		perPage  int
+		requests int
+	}{
+		{"single partial page", 12, 30, 1},
+		{"exact page boundary", 60, 30, 2},
+		{"many small pages", 95, 30, 4},
+		{"empty", 0, 30, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := pagedServer(t, tt.total, tt.perPage, &requests)
+			defer server.Close()
+
+			contributors, err := NewClient(WithBaseURL(server.URL)).ListContributors(context.Background(), "owner", "repo", false)
+			if err != nil {
+				t.Fatalf("ListContributors returned error: %v", err)
+			}
+			if len(contributors) != tt.total {
+				t.Errorf("Expected %d contributors, got %d", tt.total, len(contributors))
+			}
+			if requests != tt.requests {
+				t.Errorf("Expected %d requests, got %d", tt.requests, requests)
+			}
+		})
+	}
+}
+
+func TestPaginateStopsWhenCallerBreaks(t *testing.T) {
+	requests := 0
+	server := pagedServer(t, 300, 30, &requests)
+	defer server.Close()
+
+	seen := 0
+	for _, err := range NewClient(WithBaseURL(server.URL)).Contributors(context.Background(), "owner", "repo", false) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		seen++
+		if seen == 45 {
+			break
+		}
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+}
+
+func TestParseLinkHeader(t *testing.T) {
+	header := `<https://api.github.com/repositories/1/stargazers?page=2>; rel="next", ` +
+		`<https://api.github.com/repositories/1/stargazers?page=400>; rel="last"`
+
+	links := parseLinkHeader(header)
+	if got := links["next"]; got != "https://api.github.com/repositories/1/stargazers?page=2" {
+		t.Errorf("Unexpected next link %q", got)
+	}
+	if got := links["last"]; got != "https://api.github.com/repositories/1/stargazers?page=400" {
+		t.Errorf("Unexpected last link %q", got)
+	}
+	if len(parseLinkHeader("")) != 0 {
+		t.Errorf("Expected no links for empty header")
+	}
+}
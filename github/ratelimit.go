@@ -0,0 +1,223 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxRateLimitWait is the longest the client pauses for a rate
+	// limit to clear before giving up with an error.
+	DefaultMaxRateLimitWait = time.Minute
+	// DefaultMaxRetries bounds how often a rate limited request is retried.
+	DefaultMaxRetries = 3
+
+	// secondaryBackoff is the first pause after a secondary rate limit that
+	// came without Retry-After; GitHub asks for at least a minute.
+	secondaryBackoff = time.Minute
+)
+
+// Rate is the primary rate limit budget GitHub last reported for a token.
+type Rate struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// RateLimitError is returned when GitHub refuses a request because the
+// primary rate limit of the token (or the anonymous IP) is exhausted and it
+// does not reset within the client's maximum wait.
+type RateLimitError struct {
+	Rate Rate
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded. Please use a GitHub token. Limit: %d, Remaining: %d, Reset: %s",
+		e.Rate.Limit, e.Rate.Remaining, e.Rate.Reset.Format(time.RFC3339))
+}
+
+// SecondaryRateLimitError is returned when GitHub throttles a burst of
+// requests independently of the remaining budget and the suggested pause
+// exceeds the client's maximum wait.
+type SecondaryRateLimitError struct {
+	RetryAfter time.Duration
+	Message    string
+}
+
+func (e *SecondaryRateLimitError) Error() string {
+	return fmt.Sprintf("secondary rate limit exceeded, retry after %s: %s", e.RetryAfter, e.Message)
+}
+
+// RetryAfter reports how long a caller should wait before repeating a
+// request that failed with err, if err is a rate limit error.
+func RetryAfter(err error) (time.Duration, bool) {
+	var rateErr *RateLimitError
+	if errors.As(err, &rateErr) {
+		return max(time.Until(rateErr.Rate.Reset), 0), true
+	}
+	var secondaryErr *SecondaryRateLimitError
+	if errors.As(err, &secondaryErr) {
+		return secondaryErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// WithRateLimitWait sets the longest the client pauses for a rate limit to
+// clear. Zero disables waiting entirely.
+func WithRateLimitWait(maxWait time.Duration) Option {
+	return func(c *Client) {
+		c.scheduler.maxWait = maxWait
+	}
+}
+
+// WithMaxRetries sets how often a rate limited request is retried.
+func WithMaxRetries(retries int) Option {
+	return func(c *Client) {
+		c.scheduler.maxRetries = retries
+	}
+}
+
+// Rate returns the REST budget GitHub last reported for the client's token.
+// The zero Rate means no response has been seen yet.
+func (c *Client) Rate() Rate {
+	return c.scheduler.rate(c.token + " core")
+}
+
+// budgetKey names the budget a request draws from. GitHub accounts REST
+// ("core") and GraphQL requests separately, per token.
+func budgetKey(token string, req *http.Request) string {
+	if strings.HasSuffix(req.URL.Path, "/graphql") {
+		return token + " graphql"
+	}
+	return token + " core"
+}
+
+// scheduler keeps track of the rate limit budget per token and resource and
+// decides whether a request has to wait before it is sent or retried.
+type scheduler struct {
+	maxWait    time.Duration
+	maxRetries int
+	sleep      func(ctx context.Context, d time.Duration) error
+
+	mu    sync.Mutex
+	rates map[string]Rate
+}
+
+func newScheduler() *scheduler {
+	return &scheduler{
+		maxWait:    DefaultMaxRateLimitWait,
+		maxRetries: DefaultMaxRetries,
+		sleep:      sleepContext,
+		rates:      make(map[string]Rate),
+	}
+}
+
+func (s *scheduler) rate(budget string) Rate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rates[budget]
+}
+
+// observe records the budget advertised in the response headers.
+func (s *scheduler) observe(budget string, header http.Header) {
+	rate, ok := parseRate(header)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	s.rates[budget] = rate
+	s.mu.Unlock()
+}
+
+// wait blocks until the budget has requests left again. It fails fast when
+// the known reset lies beyond the maximum wait.
+func (s *scheduler) wait(ctx context.Context, budget string) error {
+	rate := s.rate(budget)
+	if rate.Limit == 0 || rate.Remaining > 0 {
+		return nil
+	}
+
+	pause := time.Until(rate.Reset)
+	if pause <= 0 {
+		return nil
+	}
+	if pause > s.maxWait {
+		return &RateLimitError{Rate: rate}
+	}
+	return s.sleep(ctx, pause)
+}
+
+// backoff decides whether a request that failed with err on the given
+// attempt should be retried, and after how long.
+func (s *scheduler) backoff(err error, attempt int) (time.Duration, bool) {
+	if attempt >= s.maxRetries {
+		return 0, false
+	}
+
+	var pause time.Duration
+	var rateErr *RateLimitError
+	var secondaryErr *SecondaryRateLimitError
+	switch {
+	case errors.As(err, &rateErr):
+		pause = max(time.Until(rateErr.Rate.Reset), 0)
+	case errors.As(err, &secondaryErr):
+		pause = secondaryErr.RetryAfter
+	default:
+		return 0, false
+	}
+	return pause, pause <= s.maxWait
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func parseRate(header http.Header) (Rate, bool) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return Rate{}, false
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return Rate{}, false
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return Rate{}, false
+	}
+	return Rate{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}, true
+}
+
+// rateLimitError classifies a 403 or 429 response. It returns nil when the
+// response is an ordinary permission error rather than a rate limit.
+func rateLimitError(resp *http.Response, body string, attempt int) error {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return &SecondaryRateLimitError{RetryAfter: time.Duration(seconds) * time.Second, Message: body}
+	}
+
+	if rate, ok := parseRate(resp.Header); ok && rate.Remaining == 0 {
+		return &RateLimitError{Rate: rate}
+	}
+
+	if strings.Contains(strings.ToLower(body), "secondary rate limit") || resp.StatusCode == http.StatusTooManyRequests {
+		return &SecondaryRateLimitError{RetryAfter: secondaryBackoff << attempt, Message: body}
+	}
+	return nil
+}
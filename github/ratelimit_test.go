@@ -0,0 +1,149 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// recordSleeps replaces the scheduler's sleep so tests observe the pauses
+// instead of waiting them out.
+func recordSleeps(c *Client) *[]time.Duration {
+	var sleeps []time.Duration
+	c.scheduler.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	return &sleeps
+}
+
+func TestSchedulerRetriesSecondaryRateLimit(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"You have exceeded a secondary rate limit."}`))
+			return
+		}
+		w.Write([]byte(`{"login":"octocat"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	sleeps := recordSleeps(client)
+
+	if _, err := client.GetUser(context.Background(), "octocat"); err != nil {
+		t.Fatalf("GetUser returned error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+	if len(*sleeps) != 1 || (*sleeps)[0] != 7*time.Second {
+		t.Errorf("Expected a single 7s pause, got %v", *sleeps)
+	}
+}
+
+func TestSchedulerWaitsForPrimaryReset(t *testing.T) {
+	reset := time.Now().Add(20 * time.Second)
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(reset.Unix()))
+		if calls == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Write([]byte(`{"login":"octocat"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	sleeps := recordSleeps(client)
+
+	if _, err := client.GetUser(context.Background(), "octocat"); err != nil {
+		t.Fatalf("GetUser returned error: %v", err)
+	}
+	// One pause from the retry decision, one from the pre-flight check on the
+	// retried request, both bounded by the reset time.
+	if len(*sleeps) == 0 {
+		t.Fatalf("Expected the client to pause before retrying")
+	}
+	for _, d := range *sleeps {
+		if d <= 0 || d > 21*time.Second {
+			t.Errorf("Unexpected pause %v", d)
+		}
+	}
+	if rate := client.Rate(); rate.Remaining != 4999 || rate.Limit != 5000 {
+		t.Errorf("Unexpected tracked rate %+v", rate)
+	}
+}
+
+func TestSchedulerGivesUpBeyondMaxWait(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Hour).Unix()))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	sleeps := recordSleeps(client)
+
+	_, err := client.GetUser(context.Background(), "octocat")
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("Expected RateLimitError, got %v", err)
+	}
+	if len(*sleeps) != 0 {
+		t.Errorf("Expected no pauses, got %v", *sleeps)
+	}
+
+	// The exhausted budget is remembered, so the next call fails without
+	// reaching GitHub.
+	if _, err := client.GetUser(context.Background(), "octocat"); !errors.As(err, &rateErr) {
+		t.Fatalf("Expected RateLimitError, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+	if wait, ok := RetryAfter(err); !ok || wait <= 0 {
+		t.Errorf("Expected a positive retry delay, got %v, %v", wait, ok)
+	}
+}
+
+func TestSchedulerDoesNotRetryPlainForbidden(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4000")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Hour).Unix()))
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	recordSleeps(client)
+
+	_, err := client.GetUser(context.Background(), "octocat")
+	var errResp *ErrorResponse
+	if !errors.As(err, &errResp) || errResp.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected a 403 ErrorResponse, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
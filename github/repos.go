@@ -0,0 +1,105 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+// GetRepository fetches a single repository.
+func (c *Client) GetRepository(ctx context.Context, owner, repo string) (*cu.Repository, error) {
+	var repository cu.Repository
+	if _, err := c.get(ctx, fmt.Sprintf("repos/%s/%s", owner, repo), nil, "", &repository); err != nil {
+		return nil, err
+	}
+	return &repository, nil
+}
+
+// Releases iterates over every release of a repository, newest first.
+func (c *Client) Releases(ctx context.Context, owner, repo string) iter.Seq2[cu.Release, error] {
+	return paginate[cu.Release](ctx, c, fmt.Sprintf("repos/%s/%s/releases", owner, repo), nil, "")
+}
+
+// ListReleases returns every release of a repository.
+func (c *Client) ListReleases(ctx context.Context, owner, repo string) ([]cu.Release, error) {
+	return Collect(c.Releases(ctx, owner, repo))
+}
+
+// Stargazers iterates over every stargazer of a repository in the order the
+// stars were given, together with the time of the star.
+func (c *Client) Stargazers(ctx context.Context, owner, repo string) iter.Seq2[cu.StargazerResponse, error] {
+	return paginate[cu.StargazerResponse](ctx, c, fmt.Sprintf("repos/%s/%s/stargazers", owner, repo), nil, mediaTypeStar)
+}
+
+// ListStargazers returns every stargazer of a repository.
+func (c *Client) ListStargazers(ctx context.Context, owner, repo string) ([]cu.StargazerResponse, error) {
+	return Collect(c.Stargazers(ctx, owner, repo))
+}
+
+// MaxStargazerPages is the last page of the REST stargazers endpoint GitHub
+// serves; anything beyond it answers 422 regardless of per_page.
+const MaxStargazerPages = 400
+
+// ListStargazersPage returns a single page of stargazers.
+func (c *Client) ListStargazersPage(ctx context.Context, owner, repo string, page, perPage int) ([]cu.StargazerResponse, error) {
+	query := url.Values{}
+	query.Set("page", fmt.Sprint(page))
+	query.Set("per_page", fmt.Sprint(perPage))
+
+	var stargazers []cu.StargazerResponse
+	if _, err := c.get(ctx, fmt.Sprintf("repos/%s/%s/stargazers", owner, repo), query, mediaTypeStar, &stargazers); err != nil {
+		return nil, err
+	}
+	return stargazers, nil
+}
+
+// Forks iterates over every direct fork of a repository, oldest first.
+func (c *Client) Forks(ctx context.Context, owner, repo string) iter.Seq2[cu.Repository, error] {
+	query := url.Values{}
+	query.Set("sort", "oldest")
+	return paginate[cu.Repository](ctx, c, fmt.Sprintf("repos/%s/%s/forks", owner, repo), query, "")
+}
+
+// Issues iterates over every issue and pull request of a repository, open
+// or closed, oldest first. Pull requests carry a PullRequest field.
+func (c *Client) Issues(ctx context.Context, owner, repo string) iter.Seq2[cu.Issue, error] {
+	query := url.Values{}
+	query.Set("state", "all")
+	query.Set("sort", "created")
+	query.Set("direction", "asc")
+	return paginate[cu.Issue](ctx, c, fmt.Sprintf("repos/%s/%s/issues", owner, repo), query, "")
+}
+
+// Contributors iterates over every contributor of a repository. With anon,
+// commit emails not linked to a GitHub account are listed as anonymous
+// contributors too. Empty repositories yield nothing.
+func (c *Client) Contributors(ctx context.Context, owner, repo string, anon bool) iter.Seq2[cu.Contributor, error] {
+	var query url.Values
+	if anon {
+		query = url.Values{"anon": {"1"}}
+	}
+	return paginate[cu.Contributor](ctx, c, fmt.Sprintf("repos/%s/%s/contributors", owner, repo), query, "")
+}
+
+// ListContributors returns every contributor of a repository.
+func (c *Client) ListContributors(ctx context.Context, owner, repo string, anon bool) ([]cu.Contributor, error) {
+	return Collect(c.Contributors(ctx, owner, repo, anon))
+}
+
+// Commits iterates over every commit of the default branch made since the
+// given time, newest first.
+func (c *Client) Commits(ctx context.Context, owner, repo string, since time.Time) iter.Seq2[cu.Commit, error] {
+	query := url.Values{}
+	query.Set("since", since.Format(time.RFC3339))
+	return paginate[cu.Commit](ctx, c, fmt.Sprintf("repos/%s/%s/commits", owner, repo), query, "")
+}
+
+// ListCommits returns every commit of the default branch made since the
+// given time.
+func (c *Client) ListCommits(ctx context.Context, owner, repo string, since time.Time) ([]cu.Commit, error) {
+	return Collect(c.Commits(ctx, owner, repo, since))
+}
@@ -0,0 +1,17 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+// GetUser fetches the public profile of a user.
+func (c *Client) GetUser(ctx context.Context, login string) (*cu.User, error) {
+	var user cu.User
+	if _, err := c.get(ctx, fmt.Sprintf("users/%s", login), nil, "", &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/pool"
+)
+
+// Activity metrics, selected with the metrics query parameter of
+// /activity-history. GitHub keeps no timestamps for watchers, so they have
+// no history.
+const (
+	metricStars        = "stars"
+	metricForks        = "forks"
+	metricIssuesOpened = "issues_opened"
+	metricIssuesClosed = "issues_closed"
+	metricPullsOpened  = "pulls_opened"
+	metricPullsMerged  = "pulls_merged"
+	// metricPullsClosed counts pull requests closed without being merged
+	metricPullsClosed = "pulls_closed"
+	metricReleases    = "releases"
+)
+
+var activityMetrics = []string{
+	metricStars, metricForks,
+	metricIssuesOpened, metricIssuesClosed,
+	metricPullsOpened, metricPullsMerged, metricPullsClosed,
+	metricReleases,
+}
+
+type activityOptions struct {
+	Metrics  []string
+	Interval string
+	Series   string
+}
+
+func parseActivityOptions(query url.Values) (activityOptions, error) {
+	opts := activityOptions{Metrics: activityMetrics, Interval: intervalWeekly, Series: seriesCumulative}
+
+	if metrics := query.Get("metrics"); metrics != "" {
+		opts.Metrics = nil
+		for _, metric := range strings.Split(metrics, ",") {
+			metric = strings.TrimSpace(metric)
+			if !slices.Contains(activityMetrics, metric) {
+				return opts, fmt.Errorf("unknown metric %q, expected one of %s", metric, strings.Join(activityMetrics, ", "))
+			}
+			if !slices.Contains(opts.Metrics, metric) {
+				opts.Metrics = append(opts.Metrics, metric)
+			}
+		}
+	}
+
+	switch interval := query.Get("interval"); interval {
+	case "":
+	case intervalDaily, intervalWeekly, intervalMonthly:
+		opts.Interval = interval
+	default:
+		return opts, fmt.Errorf("unknown interval %q, expected daily, weekly or monthly", interval)
+	}
+
+	switch series := query.Get("series"); series {
+	case "", seriesCumulative:
+	case seriesNew:
+		opts.Series = seriesNew
+	default:
+		return opts, fmt.Errorf("unknown series %q, expected cumulative or new", series)
+	}
+	return opts, nil
+}
+
+// activityEvents fetches the time of every event of the requested metrics,
+// in no particular order. Stars come from the precomputed star history when
+// there is one; issues and pull requests share a single walk of the issues
+// list.
+func activityEvents(ctx context.Context, r *http.Request, ref repoRef, metrics []string) (map[string][]time.Time, error) {
+	client := clientFor(ref.Config)
+	events := make(map[string][]time.Time, len(metrics))
+
+	if slices.Contains(metrics, metricStars) {
+		var history cu.StarHistory
+		if _, ok := loadPrecomputed(r, resultStarHistory, ref.Key(), &history); !ok {
+			fetched, err := getStarHistory(ctx, ref.Owner, ref.Repo, ref.Config)
+			if err != nil {
+				return nil, err
+			}
+			history = *fetched
+		}
+		times := make([]time.Time, 0, len(history.History))
+		for _, point := range history.History {
+			times = append(times, point.Date)
+		}
+		events[metricStars] = times
+	}
+
+	if slices.Contains(metrics, metricForks) {
+		var times []time.Time
+		for fork, err := range client.Forks(ctx, ref.Owner, ref.Repo) {
+			if err != nil {
+				return nil, fmt.Errorf("error fetching forks: %w", err)
+			}
+			times = append(times, fork.CreatedAt)
+		}
+		events[metricForks] = times
+	}
+
+	if slices.ContainsFunc(metrics, func(metric string) bool {
+		return strings.HasPrefix(metric, "issues_") || strings.HasPrefix(metric, "pulls_")
+	}) {
+		issueEvents := make(map[string][]time.Time)
+		for issue, err := range client.Issues(ctx, ref.Owner, ref.Repo) {
+			if err != nil {
+				return nil, fmt.Errorf("error fetching issues: %w", err)
+			}
+			if issue.PullRequest == nil {
+				issueEvents[metricIssuesOpened] = append(issueEvents[metricIssuesOpened], issue.CreatedAt)
+				if issue.ClosedAt != nil {
+					issueEvents[metricIssuesClosed] = append(issueEvents[metricIssuesClosed], *issue.ClosedAt)
+				}
+				continue
+			}
+			issueEvents[metricPullsOpened] = append(issueEvents[metricPullsOpened], issue.CreatedAt)
+			switch {
+			case issue.PullRequest.MergedAt != nil:
+				issueEvents[metricPullsMerged] = append(issueEvents[metricPullsMerged], *issue.PullRequest.MergedAt)
+			case issue.ClosedAt != nil:
+				issueEvents[metricPullsClosed] = append(issueEvents[metricPullsClosed], *issue.ClosedAt)
+			}
+		}
+		for _, metric := range metrics {
+			if times, ok := issueEvents[metric]; ok {
+				events[metric] = times
+			}
+		}
+	}
+
+	if slices.Contains(metrics, metricReleases) {
+		releases, err := getAllReleases(ctx, ref.Owner, ref.Repo, ref.Config)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching releases: %w", err)
+		}
+		var times []time.Time
+		for _, release := range releases {
+			if release.Draft {
+				continue
+			}
+			if release.PublishedAt != nil {
+				times = append(times, *release.PublishedAt)
+			} else {
+				times = append(times, release.CreatedAt)
+			}
+		}
+		events[metricReleases] = times
+	}
+
+	return events, nil
+}
+
+// activityHistory buckets the events of every metric on the same intervals,
+// from the interval of the earliest event up to the one holding now.
+func activityHistory(name string, events map[string][]time.Time, opts activityOptions, now time.Time) *cu.ActivityHistory {
+	history := &cu.ActivityHistory{
+		RepoName: name,
+		Interval: opts.Interval,
+		Series:   opts.Series,
+		Metrics:  make([]cu.ActivitySeries, 0, len(opts.Metrics)),
+	}
+
+	cumulative := make(map[string][]cu.StarPoint, len(opts.Metrics))
+	var first time.Time
+	for _, metric := range opts.Metrics {
+		points := cumulativeStars(events[metric])
+		if len(points) > 0 && (first.IsZero() || points[0].Date.Before(first)) {
+			first = points[0].Date
+		}
+		cumulative[metric] = points
+	}
+
+	for _, metric := range opts.Metrics {
+		series := cu.ActivitySeries{
+			Metric: metric,
+			Total:  len(cumulative[metric]),
+			Points: make([]cu.ActivityPoint, 0),
+		}
+		if !first.IsZero() {
+			points := bucketPoints(cumulative[metric], first, now, opts.Interval)
+			if opts.Series == seriesNew {
+				differencePoints(points)
+			}
+			for _, point := range points {
+				series.Points = append(series.Points, cu.ActivityPoint{Date: point.Date, Count: point.Stars})
+			}
+		}
+		history.Metrics = append(history.Metrics, series)
+	}
+	return history
+}
+
+func HandleActivityHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := requestContext(r)
+	defer cancel()
+
+	repos := r.URL.Query()["repo"]
+	if len(repos) == 0 {
+		http.Error(w, "At least one repository URL is required", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := parseActivityOptions(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Make token optional
+	var config *cu.Config
+	authHeader := r.Header.Get("Authorization")
+	if authHeader != "" {
+		token := strings.TrimPrefix(authHeader, "Bearer ")
+		token = strings.TrimSpace(token)
+		config = &cu.Config{GithubToken: token}
+	}
+
+	refs := make([]repoRef, 0, len(repos))
+	for _, repoURL := range repos {
+		host, owner, repo, err := parseRepoURL(repoURL)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid repository URL: %v", err), http.StatusBadRequest)
+			return
+		}
+		refs = append(refs, repoRef{Owner: owner, Repo: repo, Config: forHost(config, host)})
+	}
+
+	now := time.Now().UTC()
+	results := pool.Map(ctx, concurrency, refs, func(ctx context.Context, ref repoRef) (*cu.ActivityHistory, error) {
+		events, err := activityEvents(ctx, r, ref, opts.Metrics)
+		if err != nil {
+			return nil, err
+		}
+		return activityHistory(ref.Name(), events, opts, now), nil
+	})
+
+	result := cu.MultiRepoActivity{
+		Repositories: make([]cu.ActivityHistory, 0, len(refs)),
+	}
+	failed := -1
+	for i, res := range results {
+		if res.Err != nil {
+			if failed < 0 {
+				failed = i
+			}
+			result.Errors = append(result.Errors, cu.RepoError{RepoName: refs[i].Name(), Error: res.Err.Error()})
+			continue
+		}
+		result.Repositories = append(result.Repositories, *res.Value)
+	}
+
+	// Partial results are still useful; only fail when nothing was fetched
+	if len(result.Repositories) == 0 {
+		writeFetchError(w, refs[failed].Config, results[failed].Err)
+		return
+	}
+
+	writeRateLimitHeaders(w, refsConfig(refs))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
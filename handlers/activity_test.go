@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+func TestParseActivityOptions(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected activityOptions
+		hasError bool
+	}{
+		{"", activityOptions{Metrics: activityMetrics, Interval: intervalWeekly, Series: seriesCumulative}, false},
+		{"metrics=forks,stars,forks&interval=monthly&series=new", activityOptions{Metrics: []string{metricForks, metricStars}, Interval: intervalMonthly, Series: seriesNew}, false},
+		{"metrics=watchers", activityOptions{}, true},
+		{"interval=yearly", activityOptions{}, true},
+		{"series=total", activityOptions{}, true},
+	}
+
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		opts, err := parseActivityOptions(query)
+		if (err != nil) != tt.hasError {
+			t.Errorf("%q: expected error %v, got %v", tt.query, tt.hasError, err)
+			continue
+		}
+		if !tt.hasError && !reflect.DeepEqual(opts, tt.expected) {
+			t.Errorf("%q: expected %+v, got %+v", tt.query, tt.expected, opts)
+		}
+	}
+}
+
+func activityCounts(series cu.ActivitySeries) []int {
+	counts := make([]int, 0, len(series.Points))
+	for _, point := range series.Points {
+		counts = append(counts, point.Count)
+	}
+	return counts
+}
+
+func TestActivityHistory(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+	events := map[string][]time.Time{
+		metricStars: {day(20), day(2), day(3)},
+		metricForks: {day(16)},
+	}
+	now := day(24)
+
+	history := activityHistory("owner/repo", events, activityOptions{
+		Metrics:  []string{metricStars, metricForks, metricReleases},
+		Interval: intervalWeekly,
+		Series:   seriesCumulative,
+	}, now)
+
+	if len(history.Metrics) != 3 {
+		t.Fatalf("Expected 3 metrics, got %+v", history.Metrics)
+	}
+	// Weeks start on Mondays: Jan 1, 8, 15 and 22
+	expected := map[string][]int{
+		metricStars:    {2, 2, 3, 3},
+		metricForks:    {0, 0, 1, 1},
+		metricReleases: {0, 0, 0, 0},
+	}
+	for _, series := range history.Metrics {
+		if got := activityCounts(series); !reflect.DeepEqual(got, expected[series.Metric]) {
+			t.Errorf("Expected %s %v, got %v", series.Metric, expected[series.Metric], got)
+		}
+		if !series.Points[0].Date.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected %s to start on the week of the first event, got %v", series.Metric, series.Points[0].Date)
+		}
+	}
+	if history.Metrics[0].Total != 3 || history.Metrics[2].Total != 0 {
+		t.Errorf("Unexpected totals %+v", history.Metrics)
+	}
+
+	history = activityHistory("owner/repo", events, activityOptions{
+		Metrics:  []string{metricStars},
+		Interval: intervalWeekly,
+		Series:   seriesNew,
+	}, now)
+	if got := activityCounts(history.Metrics[0]); !reflect.DeepEqual(got, []int{2, 0, 1, 0}) {
+		t.Errorf("Expected new stars per week [2 0 1 0], got %v", got)
+	}
+
+	history = activityHistory("owner/repo", nil, activityOptions{Metrics: []string{metricForks}, Interval: intervalDaily}, now)
+	if len(history.Metrics[0].Points) != 0 {
+		t.Errorf("Expected no points without events, got %+v", history.Metrics[0].Points)
+	}
+}
+
+func TestHandleActivityHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/forks":
+			w.Write([]byte(`[{"full_name":"fork/repo","created_at":"2024-01-03T00:00:00Z"}]`))
+		case "/repos/owner/repo/issues":
+			if r.URL.Query().Get("state") != "all" {
+				t.Errorf("Expected issues of every state, got %q", r.URL.Query().Get("state"))
+			}
+			w.Write([]byte(`[
+				{"number":1,"state":"closed","created_at":"2024-01-01T00:00:00Z","closed_at":"2024-01-09T00:00:00Z"},
+				{"number":2,"state":"closed","created_at":"2024-01-02T00:00:00Z","closed_at":"2024-01-03T00:00:00Z","pull_request":{"merged_at":"2024-01-03T00:00:00Z"}},
+				{"number":3,"state":"closed","created_at":"2024-01-10T00:00:00Z","closed_at":"2024-01-11T00:00:00Z","pull_request":{"merged_at":null}},
+				{"number":4,"state":"open","created_at":"2024-01-10T00:00:00Z","closed_at":null,"pull_request":{"merged_at":null}}
+			]`))
+		case "/repos/owner/repo/releases":
+			w.Write([]byte(`[
+				{"tag_name":"v2","created_at":"2024-01-12T00:00:00Z","published_at":"2024-01-12T00:00:00Z"},
+				{"tag_name":"v3","draft":true,"created_at":"2024-01-13T00:00:00Z"}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+
+	req := httptest.NewRequest(http.MethodGet, "/activity-history?repo=https://github.com/owner/repo&repo=https://github.com/owner/missing&metrics=forks,issues_opened,issues_closed,pulls_opened,pulls_merged,pulls_closed,releases", nil)
+	rr := httptest.NewRecorder()
+	HandleActivityHistory(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var result cu.MultiRepoActivity
+	if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if len(result.Repositories) != 1 || len(result.Errors) != 1 || result.Errors[0].RepoName != "owner/missing" {
+		t.Fatalf("Expected owner/repo to succeed and owner/missing to fail, got %+v", result)
+	}
+
+	totals := make(map[string]int)
+	for _, series := range result.Repositories[0].Metrics {
+		totals[series.Metric] = series.Total
+	}
+	expected := map[string]int{
+		metricForks:        1,
+		metricIssuesOpened: 1,
+		metricIssuesClosed: 1,
+		metricPullsOpened:  3,
+		metricPullsMerged:  1,
+		metricPullsClosed:  1,
+		metricReleases:     1,
+	}
+	if !reflect.DeepEqual(totals, expected) {
+		t.Errorf("Expected totals %v, got %v", expected, totals)
+	}
+}
+
+func TestHandleActivityHistory_RateLimited(t *testing.T) {
+	reset := time.Now().Add(time.Hour)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(reset.Unix()))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+
+	for _, metric := range []string{metricForks, metricIssuesOpened, metricReleases} {
+		req := httptest.NewRequest(http.MethodGet, "/activity-history?repo=https://github.com/owner/repo&metrics="+metric, nil)
+		rr := httptest.NewRecorder()
+		HandleActivityHistory(rr, req)
+
+		if rr.Code != http.StatusTooManyRequests {
+			t.Errorf("%s: expected status code %v, got %v", metric, http.StatusTooManyRequests, rr.Code)
+		}
+	}
+}
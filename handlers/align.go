@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+// Alignment origins, selected with the align query parameter of
+// /star-history
+const (
+	alignFirstStar = "first_star"
+	alignCreated   = "created"
+)
+
+// alignOptions configure the optional aligned series of /star-history
+type alignOptions struct {
+	// By is first_star or created; empty disables alignment
+	By string
+	// Normalize adds the share of the current stars to every point
+	Normalize bool
+}
+
+func parseAlignOptions(query url.Values) (alignOptions, error) {
+	var opts alignOptions
+
+	switch by := query.Get("align"); by {
+	case "", alignFirstStar, alignCreated:
+		opts.By = by
+	default:
+		return opts, fmt.Errorf("unknown align %q, expected first_star or created", by)
+	}
+
+	opts.Normalize = query.Get("normalize") == "1"
+	if opts.Normalize && opts.By == "" {
+		return opts, fmt.Errorf("normalize requires align")
+	}
+	return opts, nil
+}
+
+// alignOrigin returns the time day 0 of an aligned history stands for: the
+// creation of the repository, or else its first star
+func alignOrigin(history *cu.StarHistory, created time.Time) time.Time {
+	if !created.IsZero() {
+		return created
+	}
+	return history.History[0].Date
+}
+
+// alignStarHistory rebases history to whole days since origin. It is called
+// after aggregation, so buckets are measured from the start of the origin's
+// own bucket and day 0 is always the first one. Points falling on the same
+// day are merged: the last total wins for the cumulative series, gains add
+// up for the new series. With normalize every point also holds its share
+// of current, the stars of the repository today.
+func alignStarHistory(history *cu.StarHistory, by string, origin time.Time, current int, normalize bool) cu.AlignedStarHistory {
+	aligned := cu.AlignedStarHistory{
+		RepoName:     history.RepoName,
+		Align:        by,
+		Origin:       origin,
+		CurrentStars: current,
+		Points:       make([]cu.AlignedStarPoint, 0, len(history.History)),
+	}
+	if history.Interval != "" {
+		origin = bucketStart(origin, history.Interval)
+	}
+
+	for _, point := range history.History {
+		day := max(int(point.Date.Sub(origin)/(24*time.Hour)), 0)
+		if n := len(aligned.Points); n > 0 && aligned.Points[n-1].Day == day {
+			if history.Series == seriesNew {
+				aligned.Points[n-1].Stars += point.Stars
+			} else {
+				aligned.Points[n-1].Stars = point.Stars
+			}
+			continue
+		}
+		aligned.Points = append(aligned.Points, cu.AlignedStarPoint{Day: day, Stars: point.Stars})
+	}
+
+	if normalize && current > 0 {
+		for i := range aligned.Points {
+			percent := math.Round(float64(aligned.Points[i].Stars)/float64(current)*10000) / 100
+			aligned.Points[i].Percent = &percent
+		}
+	}
+	return aligned
+}
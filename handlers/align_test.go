@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+func TestParseAlignOptions(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected alignOptions
+		hasError bool
+	}{
+		{"", alignOptions{}, false},
+		{"align=first_star", alignOptions{By: alignFirstStar}, false},
+		{"align=created&normalize=1", alignOptions{By: alignCreated, Normalize: true}, false},
+		{"align=birthday", alignOptions{}, true},
+		{"normalize=1", alignOptions{}, true},
+	}
+
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		opts, err := parseAlignOptions(query)
+		if (err != nil) != tt.hasError {
+			t.Errorf("%q: expected error %v, got %v", tt.query, tt.hasError, err)
+			continue
+		}
+		if !tt.hasError && opts != tt.expected {
+			t.Errorf("%q: expected %+v, got %+v", tt.query, tt.expected, opts)
+		}
+	}
+}
+
+func alignedDays(aligned cu.AlignedStarHistory) [][2]int {
+	var points [][2]int
+	for _, point := range aligned.Points {
+		points = append(points, [2]int{point.Day, point.Stars})
+	}
+	return points
+}
+
+func TestAlignStarHistory(t *testing.T) {
+	start := time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)
+	// 2, 0, 1 and 3 stars on four consecutive days
+	history := &cu.StarHistory{RepoName: "owner/repo", History: dailyStarHistory(start, []int{2, 0, 1, 3})}
+
+	aligned := alignStarHistory(history, alignFirstStar, alignOrigin(history, time.Time{}), 6, false)
+	if expected := [][2]int{{0, 2}, {2, 3}, {3, 6}}; !reflect.DeepEqual(alignedDays(aligned), expected) {
+		t.Errorf("Expected points %v, got %v", expected, alignedDays(aligned))
+	}
+	if aligned.Points[0].Percent != nil {
+		t.Errorf("Expected no percentages without normalize")
+	}
+
+	// Created ten days before the first star, normalized to the current stars
+	created := start.AddDate(0, 0, -10)
+	aligned = alignStarHistory(history, alignCreated, alignOrigin(history, created), 6, true)
+	if expected := [][2]int{{10, 2}, {12, 3}, {13, 6}}; !reflect.DeepEqual(alignedDays(aligned), expected) {
+		t.Errorf("Expected points %v, got %v", expected, alignedDays(aligned))
+	}
+	if !aligned.Origin.Equal(created) || aligned.Align != alignCreated || aligned.CurrentStars != 6 {
+		t.Errorf("Unexpected aligned history %+v", aligned)
+	}
+	var percents []float64
+	for _, point := range aligned.Points {
+		percents = append(percents, *point.Percent)
+	}
+	if expected := []float64{33.33, 50, 100}; !reflect.DeepEqual(percents, expected) {
+		t.Errorf("Expected percentages %v, got %v", expected, percents)
+	}
+}
+
+func TestAlignStarHistory_Aggregated(t *testing.T) {
+	// Wednesday, so the first weekly bucket starts two days earlier
+	start := time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)
+	history := &cu.StarHistory{History: dailyStarHistory(start, []int{1, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 4})}
+	origin := alignOrigin(history, time.Time{})
+
+	aggregateStarHistory(history, starHistoryOptions{Interval: intervalWeekly, Series: seriesNew})
+	aligned := alignStarHistory(history, alignFirstStar, origin, 7, false)
+
+	if expected := [][2]int{{0, 1}, {7, 2}, {14, 4}}; !reflect.DeepEqual(alignedDays(aligned), expected) {
+		t.Errorf("Expected weekly points %v, got %v", expected, alignedDays(aligned))
+	}
+}
+
+func TestHandleStarHistory_Aligned(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/old":
+			w.Write([]byte(`{"full_name":"owner/old","created_at":"2015-01-01T00:00:00Z"}`))
+		case "/repos/owner/old/stargazers":
+			w.Write([]byte(`[{"starred_at":"2015-01-11T00:00:00Z"},{"starred_at":"2015-01-21T00:00:00Z"}]`))
+		case "/repos/owner/new":
+			w.Write([]byte(`{"full_name":"owner/new","created_at":"2023-06-01T00:00:00Z"}`))
+		case "/repos/owner/new/stargazers":
+			w.Write([]byte(`[{"starred_at":"2023-06-01T00:00:00Z"},{"starred_at":"2023-06-03T00:00:00Z"},{"starred_at":"2023-06-03T06:00:00Z"},{"starred_at":"2023-06-05T00:00:00Z"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+
+	req := httptest.NewRequest(http.MethodGet, "/star-history?repo=https://github.com/owner/old&repo=https://github.com/owner/new&align=created&normalize=1", nil)
+	rr := httptest.NewRecorder()
+	HandleStarHistory(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var result cu.MultiRepoStarHistory
+	if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if len(result.Repositories) != 2 || len(result.Repositories[0].History) != 2 {
+		t.Errorf("Expected the raw histories alongside the aligned ones, got %+v", result.Repositories)
+	}
+	if len(result.Aligned) != 2 {
+		t.Fatalf("Expected 2 aligned histories, got %+v", result.Aligned)
+	}
+	if expected := [][2]int{{10, 1}, {20, 2}}; !reflect.DeepEqual(alignedDays(result.Aligned[0]), expected) {
+		t.Errorf("Expected owner/old points %v, got %v", expected, alignedDays(result.Aligned[0]))
+	}
+	if expected := [][2]int{{0, 1}, {2, 3}, {4, 4}}; !reflect.DeepEqual(alignedDays(result.Aligned[1]), expected) {
+		t.Errorf("Expected owner/new points %v, got %v", expected, alignedDays(result.Aligned[1]))
+	}
+	if last := result.Aligned[1].Points[2].Percent; last == nil || *last != 100 {
+		t.Errorf("Expected the last point at 100%%, got %v", last)
+	}
+}
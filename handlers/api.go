@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/pool"
 )
 
 func HandleRepoStats(w http.ResponseWriter, r *http.Request) {
@@ -16,18 +20,32 @@ func HandleRepoStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ctx, cancel := requestContext(r)
+	defer cancel()
+
 	repoURL := r.URL.Query().Get("repo")
 	if repoURL == "" {
 		http.Error(w, "Repository URL is required", http.StatusBadRequest)
 		return
 	}
 
-	owner, repo, err := extractRepoInfo(repoURL)
+	host, owner, repo, err := parseRepoURL(repoURL)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Invalid repository URL: %v", err), http.StatusBadRequest)
 		return
 	}
 
+	interval, err := parseVelocityInterval(r.URL.Query().Get("velocity"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter, err := parseReleaseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Make token optional
 	var config *cu.Config
 	authHeader := r.Header.Get("Authorization")
@@ -36,25 +54,63 @@ func HandleRepoStats(w http.ResponseWriter, r *http.Request) {
 		token = strings.TrimSpace(token)
 		config = &cu.Config{GithubToken: token}
 	}
+	config = forHost(config, host)
 
-	releases, err := getAllReleases(owner, repo, config)
+	ref := repoRef{Owner: owner, Repo: repo, Config: config}
+	stats, computedAt, err := fetchDownloadStats(ctx, r, ref)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeFetchError(w, config, err)
 		return
 	}
+	if !computedAt.IsZero() {
+		writeComputedAt(w, computedAt)
+	} else {
+		writeRateLimitHeaders(w, config)
+	}
 
-	stats := calculateDownloadStats(releases)
-	stats.RepoName = fmt.Sprintf("%s/%s", owner, repo)
+	filterReleases(stats, filter)
+	excludeChecksums := r.URL.Query().Get("exclude_checksums") == "1"
+	classifyDownloads(stats, excludeChecksums)
+	if filter.Group != "" {
+		stats.Lines = versionLines(stats, filter.Group)
+	}
+
+	if r.URL.Query().Get("channels") == "1" {
+		addDownloadChannels(ctx, stats, ref)
+	}
+
+	if interval != "" {
+		var snapshotList []cu.Snapshot
+		if snapshots != nil {
+			snapshotList, err = snapshots.Snapshots(ref.Key(), time.Time{}, time.Time{})
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		if excludeChecksums {
+			for _, snapshot := range snapshotList {
+				if snapshot.Downloads != nil {
+					classifyDownloads(snapshot.Downloads, true)
+				}
+			}
+		}
+		stats.Trends = downloadTrends(stats, snapshotList, interval)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
 }
+
 func HandleStarHistory(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	ctx, cancel := requestContext(r)
+	defer cancel()
+
 	// Get repositories from query parameter
 	repos := r.URL.Query()["repo"]
 	if len(repos) == 0 {
@@ -62,6 +118,12 @@ func HandleStarHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts, err := parseStarHistoryOptions(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Make token optional
 	var config *cu.Config
 	authHeader := r.Header.Get("Authorization")
@@ -71,27 +133,103 @@ func HandleStarHistory(w http.ResponseWriter, r *http.Request) {
 		config = &cu.Config{GithubToken: token}
 	}
 
-	// Fetch star history for all repositories
-	result := cu.MultiRepoStarHistory{
-		Repositories: make([]cu.StarHistory, 0, len(repos)),
-	}
-
+	refs := make([]repoRef, 0, len(repos))
 	for _, repoURL := range repos {
-		owner, repo, err := extractRepoInfo(repoURL)
+		host, owner, repo, err := parseRepoURL(repoURL)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Invalid repository URL: %v", err), http.StatusBadRequest)
 			return
 		}
+		refs = append(refs, repoRef{Owner: owner, Repo: repo, Config: forHost(config, host)})
+	}
 
-		history, err := getStarHistory(owner, repo, config)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+	// Fetch star history for all repositories
+	results := pool.Map(ctx, concurrency, refs, func(ctx context.Context, ref repoRef) (*cu.StarHistory, error) {
+		// Only the full REST history is precomputed
+		if opts.Mode == starModeREST {
+			var history cu.StarHistory
+			if _, ok := loadPrecomputed(r, resultStarHistory, ref.Key(), &history); ok {
+				return &history, nil
+			}
+		}
+		return fetchStarHistory(ctx, ref.Owner, ref.Repo, ref.Config, opts)
+	})
+
+	// Aligning by creation needs the creation date of every fetched
+	// repository, and star losses are measured against the live star count
+	var repositories []pool.Result[*cu.Repository]
+	if opts.Align.By == alignCreated || opts.Events {
+		repositories = pool.Map(ctx, concurrency, refs, func(ctx context.Context, ref repoRef) (*cu.Repository, error) {
+			return clientFor(ref.Config).GetRepository(ctx, ref.Owner, ref.Repo)
+		})
+	}
+
+	result := cu.MultiRepoStarHistory{
+		Repositories: make([]cu.StarHistory, 0, len(repos)),
+	}
+	failed := -1
+	for i, res := range results {
+		if res.Err != nil {
+			if failed < 0 {
+				failed = i
+			}
+			result.Errors = append(result.Errors, cu.RepoError{RepoName: refs[i].Name(), Error: res.Err.Error()})
+			continue
+		}
+		// Forecasts and events are derived from the full cumulative history
+		if opts.Forecast.Model != "" {
+			res.Value.Forecast = forecastStars(res.Value.History, res.Value.Sampled, opts.Forecast, time.Now().UTC())
+		}
+		if opts.Events {
+			var snapshotList []cu.Snapshot
+			if snapshots != nil {
+				var err error
+				if snapshotList, err = snapshots.Snapshots(refs[i].Key(), time.Time{}, time.Time{}); err != nil {
+					log.Printf("Error loading snapshots of %s: %v", refs[i].Key(), err)
+				}
+			}
+			current := -1
+			if repository := repositories[i]; repository.Err != nil {
+				log.Printf("Error fetching the star count of %s: %v", refs[i].Key(), repository.Err)
+			} else {
+				current = repository.Value.StargazersCount
+			}
+			res.Value.Events = detectStarEvents(res.Value, snapshotList, current)
 		}
+		// The origin and current stars come from the history before it is
+		// aggregated, the aligned points from the history as returned
+		var origin time.Time
+		current := 0
+		align := opts.Align.By != "" && len(res.Value.History) > 0
+		if align && opts.Align.By == alignCreated && repositories[i].Err != nil {
+			result.Errors = append(result.Errors, cu.RepoError{
+				RepoName: refs[i].Name(),
+				Error:    fmt.Sprintf("error fetching creation date: %v", repositories[i].Err),
+			})
+			align = false
+		}
+		if align {
+			var createdAt time.Time
+			if opts.Align.By == alignCreated {
+				createdAt = repositories[i].Value.CreatedAt
+			}
+			origin = alignOrigin(res.Value, createdAt)
+			current = res.Value.History[len(res.Value.History)-1].Stars
+		}
+		aggregateStarHistory(res.Value, opts)
+		result.Repositories = append(result.Repositories, *res.Value)
+		if align {
+			result.Aligned = append(result.Aligned, alignStarHistory(res.Value, opts.Align.By, origin, current, opts.Align.Normalize))
+		}
+	}
 
-		result.Repositories = append(result.Repositories, *history)
+	// Partial results are still useful; only fail when nothing was fetched
+	if len(result.Repositories) == 0 {
+		writeFetchError(w, refs[failed].Config, results[failed].Err)
+		return
 	}
 
+	writeRateLimitHeaders(w, refsConfig(refs))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
 }
@@ -102,12 +240,21 @@ func HandleOrgContributors(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ctx, cancel := requestContext(r)
+	defer cancel()
+
 	org := r.URL.Query().Get("org")
 	if org == "" {
 		http.Error(w, "Organization name is required", http.StatusBadRequest)
 		return
 	}
 
+	host := r.URL.Query().Get("host")
+	if !knownHost(host) {
+		http.Error(w, fmt.Sprintf("Unknown GitHub host: %s", host), http.StatusBadRequest)
+		return
+	}
+
 	// Make token optional
 	var config *cu.Config
 	authHeader := r.Header.Get("Authorization")
@@ -116,13 +263,32 @@ func HandleOrgContributors(w http.ResponseWriter, r *http.Request) {
 		token = strings.TrimSpace(token)
 		config = &cu.Config{GithubToken: token}
 	}
+	config = forHost(config, host)
+
+	filter, err := parseContributorFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Only the default counts are precomputed
+	var precomputed cu.OrganizationStats
+	if filter == defaultContributorFilter {
+		if computedAt, ok := loadPrecomputed(r, resultOrgContributors, orgKey(org, host), &precomputed); ok {
+			writeComputedAt(w, computedAt)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(precomputed)
+			return
+		}
+	}
 
-	stats, err := getOrgContributors(org, config)
+	stats, err := getOrgContributors(ctx, org, config, filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeFetchError(w, config, err)
 		return
 	}
 
+	writeRateLimitHeaders(w, config)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
 }
@@ -133,6 +299,9 @@ func HandleActiveContributors(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ctx, cancel := requestContext(r)
+	defer cancel()
+
 	repoURL := r.URL.Query().Get("repo")
 	orgName := r.URL.Query().Get("org")
 
@@ -141,21 +310,33 @@ func HandleActiveContributors(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filter, err := parseContributorFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var config *cu.Config
 
 	// If repoURL is provided, handle single repository
 	if repoURL != "" {
-		owner, repo, err := extractRepoInfo(repoURL)
+		host, owner, repo, err := parseRepoURL(repoURL)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Invalid repository URL: %v", err), http.StatusBadRequest)
 			return
 		}
-		handleSingleRepo(w, owner, repo, config)
+		handleSingleRepo(ctx, w, owner, repo, forHost(config, host), filter)
+		return
+	}
+
+	host := r.URL.Query().Get("host")
+	if !knownHost(host) {
+		http.Error(w, fmt.Sprintf("Unknown GitHub host: %s", host), http.StatusBadRequest)
 		return
 	}
 
 	// Handle organization-wide contributors
-	handleOrganization(w, orgName, config)
+	handleOrganization(ctx, w, orgName, forHost(config, host), filter)
 }
 
 func HandleStargazers(w http.ResponseWriter, r *http.Request) {
@@ -164,6 +345,9 @@ func HandleStargazers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ctx, cancel := requestContext(r)
+	defer cancel()
+
 	// Get query parameters
 	owner := r.URL.Query().Get("owner")
 	repo := r.URL.Query().Get("repo")
@@ -173,6 +357,12 @@ func HandleStargazers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	host := r.URL.Query().Get("host")
+	if !knownHost(host) {
+		http.Error(w, fmt.Sprintf("Unknown GitHub host: %s", host), http.StatusBadRequest)
+		return
+	}
+
 	// Parse page number
 	page, err := strconv.Atoi(r.URL.Query().Get("page"))
 	if err != nil || page < 1 {
@@ -188,9 +378,10 @@ func HandleStargazers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Fetch stargazers
-	stargazers, hasMore, total, err := fetchStargazers(owner, repo, token, page)
+	config := &cu.Config{GithubToken: token, Host: host}
+	stargazers, hasMore, total, err := fetchStargazers(ctx, owner, repo, config, page)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeFetchError(w, config, err)
 		return
 	}
 
@@ -213,6 +404,7 @@ func HandleStargazers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Set response headers
+	writeRateLimitHeaders(w, config)
 	w.Header().Set("Content-Type", "application/json")
 
 	// Encode and send response
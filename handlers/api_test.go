@@ -1,12 +1,24 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/keploy/gitstats/github"
 )
 
+// useFakeGitHub points the handlers at server for the duration of the test.
+func useFakeGitHub(t *testing.T, server *httptest.Server) {
+	t.Helper()
+	previous := githubClient
+	SetGitHubClient(github.NewClient(github.WithBaseURL(server.URL)))
+	t.Cleanup(func() { SetGitHubClient(previous) })
+}
+
 // Test generated using Keploy
 func TestHandleRepoStats_MethodNotAllowed(t *testing.T) {
 	req, err := http.NewRequest(http.MethodPost, "/repo-stats", nil)
@@ -176,3 +188,51 @@ func TestHandleActiveContributors_ValidOrgAndRepo(t *testing.T) {
 		t.Errorf("Expected non-empty response body")
 	}
 }
+
+func TestHandleRepoStats_RateLimited(t *testing.T) {
+	reset := time.Now().Add(time.Hour)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(reset.Unix()))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+
+	req := httptest.NewRequest(http.MethodGet, "/repo-stats?repo=https://github.com/keploy/keploy", nil)
+	rr := httptest.NewRecorder()
+	HandleRepoStats(rr, req)
+
+	if rr.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status code %v, got %v", http.StatusTooManyRequests, rr.Code)
+	}
+	if rr.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected a Retry-After header")
+	}
+	if got := rr.Header().Get("X-GitHub-RateLimit-Remaining"); got != "0" {
+		t.Errorf("Expected remaining budget 0, got %q", got)
+	}
+}
+
+func TestHandleRepoStats_EndpointDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+
+	endpointTimeouts = map[string]time.Duration{"/repo-stats": 20 * time.Millisecond}
+	t.Cleanup(func() { endpointTimeouts = map[string]time.Duration{} })
+
+	req := httptest.NewRequest(http.MethodGet, "/repo-stats?repo=https://github.com/keploy/keploy", nil)
+	rr := httptest.NewRecorder()
+	HandleRepoStats(rr, req)
+
+	if rr.Code != http.StatusGatewayTimeout {
+		t.Errorf("Expected status code %v, got %v", http.StatusGatewayTimeout, rr.Code)
+	}
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/pool"
+)
+
+// Release cadence trends
+const (
+	trendAccelerating     = "accelerating"
+	trendSteady           = "steady"
+	trendSlowing          = "slowing"
+	trendInsufficientData = "insufficient_data"
+
+	// trendWindow is the period whose release count is compared with the
+	// one before it
+	trendWindow = 90 * 24 * time.Hour
+	// trendThreshold is the relative change in releases per window above
+	// which the cadence counts as accelerating or slowing
+	trendThreshold = 0.25
+)
+
+// gapBuckets are the ranges of the gap distribution. The last bucket is
+// unbounded.
+var gapBuckets = []struct {
+	label string
+	upTo  time.Duration
+}{
+	{"< 1 day", 24 * time.Hour},
+	{"1-7 days", 7 * 24 * time.Hour},
+	{"1-4 weeks", 28 * 24 * time.Hour},
+	{"1-3 months", 90 * 24 * time.Hour},
+	{"> 3 months", 0},
+}
+
+// releaseTime is when a release became available. Results stored before
+// the publish date was recorded fall back to the date of the tagged commit.
+func releaseTime(release cu.ReleaseDownloadStats) time.Time {
+	if release.PublishedAt != nil {
+		return *release.PublishedAt
+	}
+	return release.CreatedAt
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// releaseCadence derives cadence statistics from the published releases,
+// relative to now. Drafts are ignored.
+func releaseCadence(name string, releases []cu.ReleaseDownloadStats, now time.Time) cu.ReleaseCadence {
+	cadence := cu.ReleaseCadence{
+		RepoName: name,
+		Monthly:  make([]cu.MonthlyCount, 0),
+		Gaps:     make([]cu.GapBucket, 0, len(gapBuckets)),
+		Trend:    trendInsufficientData,
+	}
+	for _, bucket := range gapBuckets {
+		cadence.Gaps = append(cadence.Gaps, cu.GapBucket{Label: bucket.label})
+	}
+
+	published := make([]cu.ReleaseDownloadStats, 0, len(releases))
+	for _, release := range releases {
+		if !release.Draft {
+			published = append(published, release)
+		}
+	}
+	if len(published) == 0 {
+		return cadence
+	}
+	sort.Slice(published, func(i, j int) bool {
+		return releaseTime(published[i]).Before(releaseTime(published[j]))
+	})
+
+	first, latest := releaseTime(published[0]), releaseTime(published[len(published)-1])
+	cadence.Releases = len(published)
+	cadence.FirstRelease = &first
+	cadence.LatestRelease = &latest
+
+	// Months with releases, and every month in between, up to now
+	counts := make(map[string]int)
+	for _, release := range published {
+		counts[releaseTime(release).UTC().Format("2006-01")]++
+	}
+	for month := bucketStart(first, intervalMonthly); !month.After(now); month = nextBucket(month, intervalMonthly) {
+		key := month.Format("2006-01")
+		cadence.Monthly = append(cadence.Monthly, cu.MonthlyCount{Month: key, Releases: counts[key]})
+	}
+	cadence.ReleasesPerMonth = float64(len(published)) / float64(max(len(cadence.Monthly), 1))
+
+	var gaps, leadTimes []float64
+	for i, release := range published {
+		if release.PublishedAt != nil && release.PublishedAt.After(release.CreatedAt) {
+			leadTimes = append(leadTimes, release.PublishedAt.Sub(release.CreatedAt).Hours())
+		}
+		if i == 0 {
+			continue
+		}
+
+		gap := releaseTime(release).Sub(releaseTime(published[i-1]))
+		gaps = append(gaps, gap.Hours()/24)
+		for j, bucket := range gapBuckets {
+			if bucket.upTo == 0 || gap < bucket.upTo {
+				cadence.Gaps[j].Count++
+				break
+			}
+		}
+	}
+	cadence.MeanDaysBetween = mean(gaps)
+	cadence.MedianDaysBetween = median(gaps)
+	cadence.MeanLeadTimeHours = mean(leadTimes)
+	cadence.MedianLeadTimeHours = median(leadTimes)
+
+	recent, previous := 0, 0
+	for _, release := range published {
+		age := now.Sub(releaseTime(release))
+		switch {
+		case age < 0:
+		case age <= trendWindow:
+			recent++
+		case age <= 2*trendWindow:
+			previous++
+		}
+	}
+	if recent+previous >= 2 {
+		change := float64(recent-previous) / float64(max(previous, 1))
+		switch {
+		case change > trendThreshold:
+			cadence.Trend = trendAccelerating
+		case change < -trendThreshold:
+			cadence.Trend = trendSlowing
+		default:
+			cadence.Trend = trendSteady
+		}
+	}
+	return cadence
+}
+
+func HandleReleaseCadence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := requestContext(r)
+	defer cancel()
+
+	repos := r.URL.Query()["repo"]
+	orgName := r.URL.Query().Get("org")
+	if orgName == "" && len(repos) == 0 {
+		http.Error(w, "Either organization name or repository URL is required", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseReleaseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Make token optional
+	var config *cu.Config
+	authHeader := r.Header.Get("Authorization")
+	if authHeader != "" {
+		token := strings.TrimPrefix(authHeader, "Bearer ")
+		token = strings.TrimSpace(token)
+		config = &cu.Config{GithubToken: token}
+	}
+
+	var refs []repoRef
+	if orgName != "" {
+		host := r.URL.Query().Get("host")
+		if !knownHost(host) {
+			http.Error(w, fmt.Sprintf("Unknown GitHub host: %s", host), http.StatusBadRequest)
+			return
+		}
+		orgConfig := forHost(config, host)
+
+		repositories, err := getOrgRepositories(ctx, orgName, orgConfig)
+		if err != nil {
+			writeFetchError(w, orgConfig, err)
+			return
+		}
+		for _, repository := range repositories {
+			refs = append(refs, repoRef{Owner: orgName, Repo: repository.Name, Config: orgConfig})
+		}
+	} else {
+		for _, repoURL := range repos {
+			host, owner, repo, err := parseRepoURL(repoURL)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid repository URL: %v", err), http.StatusBadRequest)
+				return
+			}
+			refs = append(refs, repoRef{Owner: owner, Repo: repo, Config: forHost(config, host)})
+		}
+	}
+
+	results := pool.Map(ctx, concurrency, refs, func(ctx context.Context, ref repoRef) (*cu.DownloadStats, error) {
+		stats, _, err := fetchDownloadStats(ctx, r, ref)
+		return stats, err
+	})
+	if err := ctx.Err(); err != nil {
+		writeFetchError(w, refsConfig(refs), err)
+		return
+	}
+
+	now := time.Now()
+	report := cu.CadenceReport{
+		OrgName:      orgName,
+		Repositories: make([]cu.ReleaseCadence, 0, len(refs)),
+	}
+	var all []cu.ReleaseDownloadStats
+	failed := -1
+	for i, res := range results {
+		if res.Err != nil {
+			if failed < 0 {
+				failed = i
+			}
+			report.Errors = append(report.Errors, cu.RepoError{RepoName: refs[i].Name(), Error: res.Err.Error()})
+			continue
+		}
+
+		filterReleases(res.Value, filter)
+		// Organizations have many repositories that never release
+		if orgName != "" && len(res.Value.Releases) == 0 {
+			continue
+		}
+		report.Repositories = append(report.Repositories, releaseCadence(refs[i].Name(), res.Value.Releases, now))
+		all = append(all, res.Value.Releases...)
+	}
+
+	if failed >= 0 && len(report.Errors) == len(refs) {
+		writeFetchError(w, refs[failed].Config, results[failed].Err)
+		return
+	}
+	report.Overall = releaseCadence(orgName, all, now)
+
+	writeRateLimitHeaders(w, refsConfig(refs))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+func TestReleaseCadence(t *testing.T) {
+	now := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	release := func(tag string, published time.Time, leadHours int) cu.ReleaseDownloadStats {
+		return cu.ReleaseDownloadStats{
+			TagName:     tag,
+			CreatedAt:   published.Add(-time.Duration(leadHours) * time.Hour),
+			PublishedAt: &published,
+		}
+	}
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+
+	releases := []cu.ReleaseDownloadStats{
+		release("v1.3.0", day(6, 20), 2),
+		release("v1.2.1", day(6, 10), 4),
+		release("v1.2.0", day(5, 10), 6),
+		release("v1.1.0", day(2, 1), 24),
+		release("v1.0.0", day(1, 31), 12),
+		{TagName: "v1.4.0", CreatedAt: day(6, 25), Draft: true},
+	}
+
+	cadence := releaseCadence("keploy/keploy", releases, now)
+
+	if cadence.Releases != 5 {
+		t.Errorf("Expected 5 releases, got %d", cadence.Releases)
+	}
+	if len(cadence.Monthly) != 6 || cadence.Monthly[0].Month != "2024-01" || cadence.Monthly[5].Releases != 2 {
+		t.Errorf("Unexpected monthly counts %+v", cadence.Monthly)
+	}
+	if cadence.ReleasesPerMonth != 5.0/6.0 {
+		t.Errorf("Expected %v releases per month, got %v", 5.0/6.0, cadence.ReleasesPerMonth)
+	}
+	// Gaps of 1, 99, 31 and 10 days
+	if cadence.MeanDaysBetween != 35.25 || cadence.MedianDaysBetween != 20.5 {
+		t.Errorf("Unexpected gaps: mean %v, median %v", cadence.MeanDaysBetween, cadence.MedianDaysBetween)
+	}
+	expectedGaps := []int{0, 1, 1, 1, 1}
+	for i, bucket := range cadence.Gaps {
+		if want := expectedGaps[i]; bucket.Count != want {
+			t.Errorf("Expected %d gaps in %s, got %d", want, bucket.Label, bucket.Count)
+		}
+	}
+	if cadence.MeanLeadTimeHours != 9.6 || cadence.MedianLeadTimeHours != 6 {
+		t.Errorf("Unexpected lead time: mean %v, median %v", cadence.MeanLeadTimeHours, cadence.MedianLeadTimeHours)
+	}
+	// Three releases in the last 90 days against two in the 90 days before
+	if cadence.Trend != trendAccelerating {
+		t.Errorf("Expected trend %s, got %s", trendAccelerating, cadence.Trend)
+	}
+}
+
+func TestReleaseCadence_Trend(t *testing.T) {
+	now := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days ...int) []cu.ReleaseDownloadStats {
+		var releases []cu.ReleaseDownloadStats
+		for _, d := range days {
+			releases = append(releases, cu.ReleaseDownloadStats{CreatedAt: now.AddDate(0, 0, -d)})
+		}
+		return releases
+	}
+
+	tests := []struct {
+		name     string
+		releases []cu.ReleaseDownloadStats
+		want     string
+	}{
+		{"no releases", nil, trendInsufficientData},
+		{"single release", daysAgo(10), trendInsufficientData},
+		{"steady", daysAgo(10, 50, 100, 150), trendSteady},
+		{"slowing", daysAgo(10, 100, 120, 150), trendSlowing},
+		{"stopped", daysAgo(100, 120), trendSlowing},
+	}
+
+	for _, tt := range tests {
+		if got := releaseCadence("", tt.releases, now).Trend; got != tt.want {
+			t.Errorf("%s: expected trend %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestHandleReleaseCadence_Org(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgs/keploy/repos":
+			w.Write([]byte(`[{"name":"keploy"},{"name":"docs"}]`))
+		case "/repos/keploy/keploy/releases":
+			w.Write([]byte(`[
+				{"tag_name":"v2.0.0","created_at":"2024-03-01T00:00:00Z","published_at":"2024-03-01T06:00:00Z"},
+				{"tag_name":"v1.0.0","created_at":"2024-01-01T00:00:00Z","published_at":"2024-01-01T02:00:00Z"}
+			]`))
+		case "/repos/keploy/docs/releases":
+			w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+
+	rr := httptest.NewRecorder()
+	HandleReleaseCadence(rr, httptest.NewRequest(http.MethodGet, "/release-cadence?org=keploy", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var report cu.CadenceReport
+	if err := json.NewDecoder(rr.Body).Decode(&report); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if len(report.Repositories) != 1 || report.Repositories[0].RepoName != "keploy/keploy" {
+		t.Fatalf("Expected only keploy/keploy, got %+v", report.Repositories)
+	}
+	if report.Overall.Releases != 2 || report.Overall.MeanLeadTimeHours != 4 {
+		t.Errorf("Unexpected overall cadence %+v", report.Overall)
+	}
+}
+
+func TestHandleReleaseCadence_MissingParameters(t *testing.T) {
+	rr := httptest.NewRecorder()
+	HandleReleaseCadence(rr, httptest.NewRequest(http.MethodGet, "/release-cadence", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %v, got %v", http.StatusBadRequest, rr.Code)
+	}
+}
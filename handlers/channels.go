@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/pool"
+)
+
+// packageTypes are the GitHub Packages registries searched for channels
+var packageTypes = []string{"container", "npm", "maven", "rubygems", "nuget"}
+
+// latestChannelTags is how many recent tags are reported per channel
+const latestChannelTags = 5
+
+// getDownloadChannels lists the GitHub Packages of the repository's owner
+// that are linked to the repository. GitHub does not count downloads of
+// source archives, so those cannot be reported as a channel.
+func getDownloadChannels(ctx context.Context, ref repoRef) ([]cu.DownloadChannel, error) {
+	client := clientFor(ref.Config)
+
+	results := pool.Map(ctx, concurrency, packageTypes, func(ctx context.Context, packageType string) ([]cu.Package, error) {
+		return client.ListOwnerPackages(ctx, ref.Owner, packageType)
+	})
+
+	var packages []cu.Package
+	for _, res := range results {
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		for _, pkg := range res.Value {
+			if pkg.Repository != nil && strings.EqualFold(pkg.Repository.FullName, ref.Name()) {
+				packages = append(packages, pkg)
+			}
+		}
+	}
+	if len(packages) == 0 {
+		return nil, nil
+	}
+
+	// Download counts are a bonus; most registries, containers included,
+	// do not report them and the GraphQL API needs a token
+	downloads, err := client.PackageDownloads(ctx, ref.Owner, ref.Repo)
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	channels := pool.Map(ctx, concurrency, packages, func(ctx context.Context, pkg cu.Package) (cu.DownloadChannel, error) {
+		channel := cu.DownloadChannel{
+			Name:     pkg.Name,
+			Type:     pkg.PackageType,
+			URL:      pkg.HTMLURL,
+			Versions: pkg.VersionCount,
+		}
+		if count, ok := downloads[pkg.Name]; ok {
+			channel.Downloads = &count
+		}
+
+		for version, err := range client.PackageVersions(ctx, pkg) {
+			if err != nil {
+				return channel, err
+			}
+			tags := version.Metadata.Container.Tags
+			if len(tags) == 0 && pkg.PackageType != "container" {
+				tags = []string{version.Name}
+			}
+			channel.LatestTags = append(channel.LatestTags, tags...)
+			if len(channel.LatestTags) >= latestChannelTags {
+				channel.LatestTags = channel.LatestTags[:latestChannelTags]
+				break
+			}
+		}
+		return channel, nil
+	})
+
+	result := make([]cu.DownloadChannel, 0, len(channels))
+	for _, res := range channels {
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		result = append(result, res.Value)
+	}
+	return result, nil
+}
+
+// addDownloadChannels merges the repository's package channels into stats.
+// Failing to list them does not fail the request, since release downloads
+// are still meaningful on their own.
+func addDownloadChannels(ctx context.Context, stats *cu.DownloadStats, ref repoRef) {
+	channels, err := getDownloadChannels(ctx, ref)
+	if err != nil {
+		stats.ChannelError = err.Error()
+		return
+	}
+
+	stats.Channels = channels
+	for _, channel := range channels {
+		if channel.Downloads != nil {
+			stats.ChannelDownloads += *channel.Downloads
+		}
+	}
+	stats.TotalAdoption = stats.TotalDownloads + stats.ChannelDownloads
+}
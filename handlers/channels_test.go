@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+func TestHandleRepoStats_Channels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/keploy/keploy/releases":
+			w.Write([]byte(`[{"tag_name":"v1.0.0","created_at":"2024-03-04T00:00:00Z","assets":[{"name":"keploy_linux_amd64.tar.gz","download_count":100}]}]`))
+		case "/orgs/keploy/packages":
+			switch r.URL.Query().Get("package_type") {
+			case "container":
+				w.Write([]byte(`[
+					{"name":"keploy","package_type":"container","version_count":2,"owner":{"login":"keploy","type":"Organization"},"repository":{"full_name":"keploy/keploy"}},
+					{"name":"other","package_type":"container","owner":{"login":"keploy","type":"Organization"},"repository":{"full_name":"keploy/other"}}
+				]`))
+			case "npm":
+				w.Write([]byte(`[{"name":"keploy-sdk","package_type":"npm","version_count":1,"owner":{"login":"keploy","type":"Organization"},"repository":{"full_name":"keploy/keploy"}}]`))
+			default:
+				w.Write([]byte(`[]`))
+			}
+		case "/orgs/keploy/packages/container/keploy/versions":
+			w.Write([]byte(`[{"name":"sha256:b","metadata":{"container":{"tags":["v1.0.0","latest"]}}},{"name":"sha256:a","metadata":{"container":{"tags":[]}}}]`))
+		case "/orgs/keploy/packages/npm/keploy-sdk/versions":
+			w.Write([]byte(`[{"name":"1.0.0"}]`))
+		case "/graphql":
+			w.Write([]byte(`{"data":{"repository":{"packages":{"nodes":[{"name":"keploy-sdk","statistics":{"downloadsTotalCount":25}}]}}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+
+	req := httptest.NewRequest(http.MethodGet, "/repo-stats?repo=https://github.com/keploy/keploy&channels=1", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr := httptest.NewRecorder()
+	HandleRepoStats(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var stats cu.DownloadStats
+	if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if stats.ChannelError != "" {
+		t.Fatalf("Unexpected channel error %s", stats.ChannelError)
+	}
+	if len(stats.Channels) != 2 {
+		t.Fatalf("Expected 2 channels, got %+v", stats.Channels)
+	}
+	container, npm := stats.Channels[0], stats.Channels[1]
+	if container.Downloads != nil || len(container.LatestTags) != 2 || container.LatestTags[1] != "latest" {
+		t.Errorf("Unexpected container channel %+v", container)
+	}
+	if npm.Downloads == nil || *npm.Downloads != 25 || npm.LatestTags[0] != "1.0.0" {
+		t.Errorf("Unexpected npm channel %+v", npm)
+	}
+	if stats.ChannelDownloads != 25 || stats.TotalAdoption != 125 {
+		t.Errorf("Expected 25 channel downloads and 125 in total, got %d and %d", stats.ChannelDownloads, stats.TotalAdoption)
+	}
+}
+
+func TestHandleRepoStats_ChannelsUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/keploy/keploy/releases" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"Requires authentication"}`))
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+
+	rr := httptest.NewRecorder()
+	HandleRepoStats(rr, httptest.NewRequest(http.MethodGet, "/repo-stats?repo=https://github.com/keploy/keploy&channels=1", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var stats cu.DownloadStats
+	if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if stats.ChannelError == "" || len(stats.Channels) != 0 {
+		t.Errorf("Expected a channel error and no channels, got %+v", stats)
+	}
+}
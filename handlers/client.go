@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/github"
+)
+
+// publicHost is the web host of public GitHub.
+const publicHost = "github.com"
+
+var (
+	// githubClient is the shared client for public GitHub every fetcher
+	// derives its per-request client from.
+	githubClient = github.NewClient()
+
+	// hostClients holds the shared clients of GitHub Enterprise Server
+	// instances, keyed by web host.
+	hostClients = map[string]*github.Client{}
+)
+
+// SetGitHubClient replaces the shared GitHub client, e.g. to point the
+// handlers at a different API root or a fake server in tests.
+func SetGitHubClient(client *github.Client) {
+	githubClient = client
+}
+
+// SetHostClient registers the client used for repositories on a GitHub
+// Enterprise Server host such as ghe.example.com. Repository URLs on that
+// host are accepted from then on.
+func SetHostClient(host string, client *github.Client) {
+	hostClients[strings.ToLower(host)] = client
+}
+
+// clientFor returns the shared client for the host in config, authenticated
+// with the token from config, if any.
+func clientFor(config *cu.Config) *github.Client {
+	client := githubClient
+	if config != nil && config.Host != "" && config.Host != publicHost {
+		if hostClient, ok := hostClients[strings.ToLower(config.Host)]; ok {
+			client = hostClient
+		}
+	}
+
+	if config == nil || config.GithubToken == "" {
+		return client
+	}
+	return client.WithToken(config.GithubToken)
+}
+
+// forHost returns a copy of config that targets host.
+func forHost(config *cu.Config, host string) *cu.Config {
+	var c cu.Config
+	if config != nil {
+		c = *config
+	}
+	c.Host = host
+	return &c
+}
+
+// knownHost reports whether repositories on host can be served.
+func knownHost(host string) bool {
+	if host == "" || host == publicHost {
+		return true
+	}
+	_, ok := hostClients[strings.ToLower(host)]
+	return ok
+}
+
+// writeRateLimitHeaders tells the caller how much GitHub budget is left for
+// the token they used on the host in config. A nil config writes nothing.
+// It must run before the response body is written.
+func writeRateLimitHeaders(w http.ResponseWriter, config *cu.Config) {
+	if config == nil {
+		return
+	}
+	rate := clientFor(config).Rate()
+	if rate.Limit == 0 {
+		return
+	}
+	w.Header().Set("X-GitHub-RateLimit-Limit", strconv.Itoa(rate.Limit))
+	w.Header().Set("X-GitHub-RateLimit-Remaining", strconv.Itoa(rate.Remaining))
+	w.Header().Set("X-GitHub-RateLimit-Reset", strconv.FormatInt(rate.Reset.Unix(), 10))
+}
+
+// refsConfig returns the config of the host all refs target, or nil when
+// they span several hosts, each with a budget of its own.
+func refsConfig(refs []repoRef) *cu.Config {
+	if len(refs) == 0 {
+		return nil
+	}
+	host := normalizeHost(refs[0].Config.Host)
+	for _, ref := range refs[1:] {
+		if normalizeHost(ref.Config.Host) != host {
+			return nil
+		}
+	}
+	return refs[0].Config
+}
+
+func normalizeHost(host string) string {
+	if host == "" {
+		return publicHost
+	}
+	return strings.ToLower(host)
+}
+
+// writeFetchError reports a failed GitHub fetch. Rate limits surface as 429
+// with Retry-After so clients can back off, missing credentials as 401 and
+// an exceeded endpoint deadline as 504; anything else is a 500.
+func writeFetchError(w http.ResponseWriter, config *cu.Config, err error) {
+	writeRateLimitHeaders(w, config)
+	if errors.Is(err, context.Canceled) {
+		// The client went away; there is nobody left to answer.
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		http.Error(w, "Timed out waiting for GitHub", http.StatusGatewayTimeout)
+		return
+	}
+	if errors.Is(err, github.ErrGraphQLAuth) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if wait, ok := github.RetryAfter(err); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
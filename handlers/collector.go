@@ -0,0 +1,453 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/schedule"
+)
+
+// Kinds of precomputed results the collector stores
+const (
+	resultRepoStats       = "repo-stats"
+	resultStarHistory     = "star-history"
+	resultOrgContributors = "org-contributors"
+
+	// collectTimeout bounds the refresh of a single target; star histories
+	// of large repositories take far longer than an interactive request.
+	collectTimeout = 30 * time.Minute
+)
+
+// collector refreshes tracked targets in the background; nil disables the
+// tracking endpoints and precomputed responses.
+var collector *Collector
+
+// SetCollector sets the collector behind the tracking endpoints.
+func SetCollector(c *Collector) {
+	collector = c
+}
+
+// Collector refreshes tracked repositories and organizations on a schedule
+// and stores the results, so page views are answered from precomputed data
+// instead of live GitHub calls.
+type Collector struct {
+	schedule schedule.Schedule
+	spec     string
+	config   *cu.Config
+	// static targets come from the server configuration and cannot be
+	// removed through the API
+	static []cu.TrackedTarget
+
+	mu      sync.Mutex
+	pending chan cu.TrackedTarget
+	// keys holds the result keys of every tracked target. It is loaded on
+	// first use and kept up to date by Track and Untrack, so requests do
+	// not read the registered targets back from the store.
+	keys map[string]bool
+}
+
+// NewCollector returns a Collector that refreshes the static targets and
+// those registered through the API whenever spec fires. token authenticates
+// the background requests.
+func NewCollector(spec, token string, static []cu.TrackedTarget) (*Collector, error) {
+	sched, err := schedule.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range static {
+		if err := validateTarget(target); err != nil {
+			return nil, err
+		}
+	}
+
+	var config *cu.Config
+	if token != "" {
+		config = &cu.Config{GithubToken: token}
+	}
+	return &Collector{
+		schedule: sched,
+		spec:     spec,
+		config:   config,
+		static:   static,
+		pending:  make(chan cu.TrackedTarget, 16),
+	}, nil
+}
+
+// Targets returns every tracked target, configured ones first.
+func (c *Collector) Targets() ([]cu.TrackedTarget, error) {
+	if snapshots == nil {
+		return slices.Clone(c.static), nil
+	}
+	registered, err := snapshots.Tracked()
+	if err != nil {
+		return nil, err
+	}
+	return append(slices.Clone(c.static), registered...), nil
+}
+
+// trackedKeys returns the result keys of every tracked target. c.mu must be
+// held.
+func (c *Collector) trackedKeys() (map[string]bool, error) {
+	if c.keys != nil {
+		return c.keys, nil
+	}
+	targets, err := c.Targets()
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		keys[targetKey(target)] = true
+	}
+	c.keys = keys
+	return keys, nil
+}
+
+// Track registers target and schedules an immediate refresh of it. A target
+// that is already tracked under another form of its URL, such as
+// github.com/owner/repo and https://github.com/owner/repo.git, is left as is.
+func (c *Collector) Track(target cu.TrackedTarget) error {
+	if snapshots == nil {
+		return fmt.Errorf("tracking targets requires snapshot storage")
+	}
+	if err := validateTarget(target); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys, err := c.trackedKeys()
+	if err != nil {
+		return err
+	}
+	key := targetKey(target)
+	if keys[key] {
+		return nil
+	}
+
+	registered, err := snapshots.Tracked()
+	if err != nil {
+		return err
+	}
+	if err := snapshots.SetTracked(append(registered, target)); err != nil {
+		return err
+	}
+	keys[key] = true
+
+	select {
+	case c.pending <- target:
+	default:
+		// The queue is full; the next scheduled run picks the target up.
+	}
+	return nil
+}
+
+// Untrack removes a target registered through the API, under any form of its
+// URL. It reports false if the target is not registered or comes from the
+// server configuration.
+func (c *Collector) Untrack(target cu.TrackedTarget) (bool, error) {
+	if snapshots == nil {
+		return false, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	registered, err := snapshots.Tracked()
+	if err != nil {
+		return false, err
+	}
+	key := targetKey(target)
+	sameKey := func(t cu.TrackedTarget) bool { return targetKey(t) == key }
+	i := slices.IndexFunc(registered, sameKey)
+	if i < 0 {
+		return false, nil
+	}
+	if err := snapshots.SetTracked(slices.Delete(registered, i, i+1)); err != nil {
+		return false, err
+	}
+	// A configured target may still want the results
+	if slices.ContainsFunc(c.static, sameKey) {
+		return true, nil
+	}
+	delete(c.keys, key)
+	return true, snapshots.DeleteResults(key)
+}
+
+// tracks reports whether the results stored under key belong to a target
+// that is still tracked.
+func (c *Collector) tracks(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys, err := c.trackedKeys()
+	if err != nil {
+		log.Printf("Error loading tracked targets: %v", err)
+		return false
+	}
+	return keys[key]
+}
+
+// Run refreshes every target once, then again whenever the schedule fires,
+// until ctx is cancelled. Newly tracked targets are refreshed right away.
+func (c *Collector) Run(ctx context.Context) {
+	c.collectAll(ctx)
+
+	for {
+		next := c.schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("Collect schedule %q never fires again, stopping the collector", c.spec)
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case target := <-c.pending:
+			timer.Stop()
+			c.collectOne(ctx, target)
+		case <-timer.C:
+			c.collectAll(ctx)
+		}
+	}
+}
+
+func (c *Collector) collectAll(ctx context.Context) {
+	targets, err := c.Targets()
+	if err != nil {
+		log.Printf("Error loading tracked targets: %v", err)
+		return
+	}
+	for _, target := range targets {
+		if ctx.Err() != nil {
+			return
+		}
+		c.collectOne(ctx, target)
+	}
+}
+
+func (c *Collector) collectOne(ctx context.Context, target cu.TrackedTarget) {
+	ctx, cancel := context.WithTimeout(ctx, collectTimeout)
+	defer cancel()
+
+	var err error
+	if target.Repo != "" {
+		err = c.collectRepo(ctx, target.Repo)
+	} else {
+		err = c.collectOrg(ctx, target.Org, target.Host)
+	}
+	if err != nil {
+		log.Printf("Error collecting %s: %v", targetName(target), err)
+	}
+}
+
+func (c *Collector) collectRepo(ctx context.Context, repoURL string) error {
+	host, owner, repo, err := parseRepoURL(repoURL)
+	if err != nil {
+		return err
+	}
+	ref := repoRef{Owner: owner, Repo: repo, Config: forHost(c.config, host)}
+
+	// Each result is collected on its own so that, for instance, a star
+	// history beyond GitHub's pagination limit does not stop the snapshot.
+	var errs []error
+	if releases, err := getAllReleases(ctx, owner, repo, ref.Config); err != nil {
+		errs = append(errs, fmt.Errorf("error collecting download stats: %w", err))
+	} else {
+		stats := calculateDownloadStats(releases)
+		stats.RepoName = ref.Name()
+		if err := snapshots.PutResult(resultRepoStats, ref.Key(), stats); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if history, err := getStarHistory(ctx, owner, repo, ref.Config); err != nil {
+		errs = append(errs, fmt.Errorf("error collecting star history: %w", err))
+	} else if err := snapshots.PutResult(resultStarHistory, ref.Key(), history); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := takeSnapshot(ctx, ref); err != nil {
+		errs = append(errs, fmt.Errorf("error taking snapshot: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Collector) collectOrg(ctx context.Context, org, host string) error {
+	config := forHost(c.config, host)
+	stats, err := getOrgContributors(ctx, org, config, defaultContributorFilter)
+	if err != nil {
+		return err
+	}
+	return snapshots.PutResult(resultOrgContributors, orgKey(org, host), stats)
+}
+
+func validateTarget(target cu.TrackedTarget) error {
+	switch {
+	case target.Repo != "" && target.Org != "":
+		return fmt.Errorf("a tracked target is either a repository or an organization, not both")
+	case target.Repo != "":
+		_, _, _, err := parseRepoURL(target.Repo)
+		return err
+	case target.Org != "":
+		if !knownHost(target.Host) {
+			return fmt.Errorf("unknown GitHub host: %s", target.Host)
+		}
+		return nil
+	default:
+		return fmt.Errorf("a tracked target needs a repository URL or an organization")
+	}
+}
+
+func targetName(target cu.TrackedTarget) string {
+	if target.Repo != "" {
+		return target.Repo
+	}
+	return orgKey(target.Org, target.Host)
+}
+
+// targetKey returns the key the results of target are stored under.
+func targetKey(target cu.TrackedTarget) string {
+	if target.Repo == "" {
+		return orgKey(target.Org, target.Host)
+	}
+	host, owner, repo, err := parseRepoURL(target.Repo)
+	if err != nil {
+		return ""
+	}
+	return repoRef{Owner: owner, Repo: repo, Config: forHost(nil, host)}.Key()
+}
+
+// orgKey identifies an organization across hosts, like repoRef.Key.
+func orgKey(org, host string) string {
+	if host == "" || host == publicHost {
+		return org
+	}
+	return host + "/" + org
+}
+
+// loadPrecomputed decodes the collector's latest result of kind for key into
+// v. Only results of targets that are still tracked and no older than
+// precomputedMaxAge are used. Requests can bypass precomputed data with
+// fresh=1.
+func loadPrecomputed(r *http.Request, kind, key string, v any) (time.Time, bool) {
+	if snapshots == nil || collector == nil || r.URL.Query().Get("fresh") == "1" {
+		return time.Time{}, false
+	}
+	if !collector.tracks(key) {
+		return time.Time{}, false
+	}
+
+	computedAt, ok, err := snapshots.Result(kind, key, v)
+	if err != nil {
+		log.Printf("Error loading precomputed %s for %s: %v", kind, key, err)
+		return time.Time{}, false
+	}
+	if ok && precomputedMaxAge > 0 && time.Since(computedAt) > precomputedMaxAge {
+		return time.Time{}, false
+	}
+	return computedAt, ok
+}
+
+// authorizeAdmin checks that r carries the admin token and writes an error
+// response if it does not.
+func authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if adminToken == "" {
+		http.Error(w, "Changing tracked targets requires GITSTATS_ADMIN_TOKEN to be configured", http.StatusForbidden)
+		return false
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Invalid or missing admin token", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// writeComputedAt tells the caller the response comes from the collector.
+func writeComputedAt(w http.ResponseWriter, computedAt time.Time) {
+	w.Header().Set("X-Gitstats-Computed-At", computedAt.Format(time.RFC3339))
+}
+
+func HandleTracked(w http.ResponseWriter, r *http.Request) {
+	if collector == nil {
+		http.Error(w, "Background collection is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		targets, err := collector.Targets()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if targets == nil {
+			targets = []cu.TrackedTarget{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Schedule string             `json:"schedule"`
+			Targets  []cu.TrackedTarget `json:"targets"`
+		}{collector.spec, targets})
+
+	case http.MethodPost:
+		if !authorizeAdmin(w, r) {
+			return
+		}
+		var target cu.TrackedTarget
+		if err := json.NewDecoder(r.Body).Decode(&target); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := validateTarget(target); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := collector.Track(target); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(target)
+
+	case http.MethodDelete:
+		if !authorizeAdmin(w, r) {
+			return
+		}
+		target := cu.TrackedTarget{
+			Repo: r.URL.Query().Get("repo"),
+			Org:  r.URL.Query().Get("org"),
+			Host: r.URL.Query().Get("host"),
+		}
+		removed, err := collector.Untrack(target)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !removed {
+			http.Error(w, "Target is not tracked or comes from the server configuration", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
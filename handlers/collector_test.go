@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+func TestCollectorServesPrecomputedStats(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch r.URL.Path {
+		case "/repos/keploy/keploy/releases":
+			w.Write([]byte(`[{"tag_name":"v1.0.0","created_at":"2024-01-01T00:00:00Z","assets":[{"name":"keploy.tar.gz","download_count":7}]}]`))
+		case "/repos/keploy/keploy/stargazers":
+			w.Write([]byte(`[{"starred_at":"2024-01-02T00:00:00Z","user":{"login":"octocat"}}]`))
+		case "/repos/keploy/keploy":
+			w.Write([]byte(`{"full_name":"keploy/keploy","stargazers_count":1}`))
+		case "/repos/keploy/keploy/contributors":
+			w.Write([]byte(`[{"login":"octocat"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+	useStore(t)
+
+	c, err := NewCollector("@daily", "", []cu.TrackedTarget{{Repo: "https://github.com/keploy/keploy"}})
+	if err != nil {
+		t.Fatalf("NewCollector returned error: %v", err)
+	}
+	previous := collector
+	SetCollector(c)
+	t.Cleanup(func() { SetCollector(previous) })
+
+	if err := c.collectRepo(context.Background(), "https://github.com/keploy/keploy"); err != nil {
+		t.Fatalf("collectRepo returned error: %v", err)
+	}
+
+	before := calls.Load()
+	rr := httptest.NewRecorder()
+	HandleRepoStats(rr, httptest.NewRequest(http.MethodGet, "/repo-stats?repo=https://github.com/keploy/keploy", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if calls.Load() != before {
+		t.Errorf("Expected precomputed stats to be served without calling GitHub")
+	}
+	if rr.Header().Get("X-Gitstats-Computed-At") == "" {
+		t.Errorf("Expected X-Gitstats-Computed-At header")
+	}
+
+	var stats cu.DownloadStats
+	if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil || stats.TotalDownloads != 7 {
+		t.Errorf("Unexpected stats %+v (%v)", stats, err)
+	}
+
+	rr = httptest.NewRecorder()
+	HandleRepoStats(rr, httptest.NewRequest(http.MethodGet, "/repo-stats?repo=https://github.com/keploy/keploy&fresh=1", nil))
+	if calls.Load() == before {
+		t.Errorf("Expected fresh=1 to bypass precomputed stats")
+	}
+	if rr.Header().Get("X-Gitstats-Computed-At") != "" {
+		t.Errorf("Expected no X-Gitstats-Computed-At header for a live response")
+	}
+
+	// Results of targets that are no longer tracked are not served
+	untracked, err := NewCollector("@daily", "", nil)
+	if err != nil {
+		t.Fatalf("NewCollector returned error: %v", err)
+	}
+	SetCollector(untracked)
+	rr = httptest.NewRecorder()
+	HandleRepoStats(rr, httptest.NewRequest(http.MethodGet, "/repo-stats?repo=https://github.com/keploy/keploy", nil))
+	if rr.Header().Get("X-Gitstats-Computed-At") != "" {
+		t.Errorf("Expected results of an untracked repository not to be served")
+	}
+}
+
+func TestCollectRepoSnapshotsWithoutStarHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/keploy/keploy/releases":
+			w.Write([]byte(`[]`))
+		case "/repos/keploy/keploy/stargazers":
+			http.Error(w, `{"message":"In order to keep the API fast for everyone, pagination is limited for this resource."}`, http.StatusUnprocessableEntity)
+		case "/repos/keploy/keploy":
+			w.Write([]byte(`{"full_name":"keploy/keploy","stargazers_count":40001}`))
+		case "/repos/keploy/keploy/contributors":
+			w.Write([]byte(`[{"login":"octocat"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+	s := useStore(t)
+
+	c, err := NewCollector("@daily", "", nil)
+	if err != nil {
+		t.Fatalf("NewCollector returned error: %v", err)
+	}
+	if err := c.collectRepo(context.Background(), "https://github.com/keploy/keploy"); err == nil {
+		t.Errorf("Expected the star history error to be reported")
+	}
+
+	snapshot, ok, err := s.Latest("keploy/keploy")
+	if !ok || err != nil || snapshot.Stars != 40001 {
+		t.Errorf("Expected a snapshot despite the star history error, got %+v (ok=%v err=%v)", snapshot, ok, err)
+	}
+	var stats cu.DownloadStats
+	if _, ok, _ := s.Result(resultRepoStats, "keploy/keploy", &stats); !ok {
+		t.Errorf("Expected download stats despite the star history error")
+	}
+}
+
+func TestLoadPrecomputedMaxAge(t *testing.T) {
+	useStore(t)
+	c, err := NewCollector("@daily", "", []cu.TrackedTarget{{Org: "keploy"}})
+	if err != nil {
+		t.Fatalf("NewCollector returned error: %v", err)
+	}
+	previous, previousMaxAge := collector, precomputedMaxAge
+	SetCollector(c)
+	t.Cleanup(func() { SetCollector(previous); precomputedMaxAge = previousMaxAge })
+
+	if err := snapshots.PutResult(resultOrgContributors, "keploy", cu.OrganizationStats{}); err != nil {
+		t.Fatalf("PutResult returned error: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/org-contributors?org=keploy", nil)
+	var stats cu.OrganizationStats
+
+	precomputedMaxAge = time.Hour
+	if _, ok := loadPrecomputed(req, resultOrgContributors, "keploy", &stats); !ok {
+		t.Errorf("Expected a recent result to be served")
+	}
+	precomputedMaxAge = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	if _, ok := loadPrecomputed(req, resultOrgContributors, "keploy", &stats); ok {
+		t.Errorf("Expected a result older than the maximum age not to be served")
+	}
+}
+
+func TestHandleTracked(t *testing.T) {
+	useStore(t)
+	c, err := NewCollector("@daily", "", []cu.TrackedTarget{{Org: "keploy"}})
+	if err != nil {
+		t.Fatalf("NewCollector returned error: %v", err)
+	}
+	previous, previousToken := collector, adminToken
+	SetCollector(c)
+	t.Cleanup(func() { SetCollector(previous); adminToken = previousToken })
+
+	tests := []struct {
+		name           string
+		adminToken     string
+		token          string
+		method         string
+		url            string
+		body           string
+		expectedStatus int
+	}{
+		{"no admin token configured", "", "secret", http.MethodPost, "/tracked", `{"repo":"https://github.com/keploy/keploy"}`, http.StatusForbidden},
+		{"missing token", "secret", "", http.MethodPost, "/tracked", `{"repo":"https://github.com/keploy/keploy"}`, http.StatusUnauthorized},
+		{"wrong token", "secret", "guess", http.MethodDelete, "/tracked?org=keploy", "", http.StatusUnauthorized},
+		{"register repository", "secret", "secret", http.MethodPost, "/tracked", `{"repo":"https://github.com/keploy/keploy"}`, http.StatusCreated},
+		{"register repository again", "secret", "secret", http.MethodPost, "/tracked", `{"repo":"github.com/keploy/keploy.git"}`, http.StatusCreated},
+		{"invalid repository", "secret", "secret", http.MethodPost, "/tracked", `{"repo":"https://example.com/keploy"}`, http.StatusBadRequest},
+		{"repository and organization", "secret", "secret", http.MethodPost, "/tracked", `{"repo":"https://github.com/keploy/keploy","org":"keploy"}`, http.StatusBadRequest},
+		{"unknown host", "secret", "secret", http.MethodPost, "/tracked", `{"org":"keploy","host":"ghe.unknown.com"}`, http.StatusBadRequest},
+		{"remove configured target", "secret", "secret", http.MethodDelete, "/tracked?org=keploy", "", http.StatusNotFound},
+		{"remove repository", "secret", "secret", http.MethodDelete, "/tracked?repo=github.com/keploy/keploy/", "", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adminToken = tt.adminToken
+			if tt.name == "remove repository" {
+				if err := snapshots.PutResult(resultRepoStats, "keploy/keploy", cu.DownloadStats{}); err != nil {
+					t.Fatalf("PutResult returned error: %v", err)
+				}
+			}
+
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rr := httptest.NewRecorder()
+			HandleTracked(rr, req)
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Expected status code %v, got %v: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	var stats cu.DownloadStats
+	if _, ok, err := snapshots.Result(resultRepoStats, "keploy/keploy", &stats); ok || err != nil {
+		t.Errorf("Expected the results of an untracked repository to be deleted, got ok=%v err=%v", ok, err)
+	}
+
+	rr := httptest.NewRecorder()
+	HandleTracked(rr, httptest.NewRequest(http.MethodGet, "/tracked", nil))
+	var response struct {
+		Targets []cu.TrackedTarget `json:"targets"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if len(response.Targets) != 1 || response.Targets[0].Org != "keploy" {
+		t.Errorf("Expected only the configured target, got %v", response.Targets)
+	}
+	if c.tracks("keploy/keploy") || !c.tracks("keploy") {
+		t.Errorf("Expected only the configured target to be tracked")
+	}
+}
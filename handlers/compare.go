@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/pool"
+)
+
+// fetchDownloadStats returns the download stats of a repository, served
+// from the collector's precomputed results when available. computedAt is
+// zero for live results.
+func fetchDownloadStats(ctx context.Context, r *http.Request, ref repoRef) (stats *cu.DownloadStats, computedAt time.Time, err error) {
+	stats = &cu.DownloadStats{}
+	if computedAt, ok := loadPrecomputed(r, resultRepoStats, ref.Key(), stats); ok {
+		return stats, computedAt, nil
+	}
+
+	releases, err := getAllReleases(ctx, ref.Owner, ref.Repo, ref.Config)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	stats = calculateDownloadStats(releases)
+	stats.RepoName = ref.Name()
+	return stats, time.Time{}, nil
+}
+
+// compareDownloads places the releases of every repository on a shared
+// axis of intervals, spanning from the oldest to the newest release of
+// any of them.
+func compareDownloads(statsList []*cu.DownloadStats, interval string) cu.DownloadComparison {
+	comparison := cu.DownloadComparison{
+		Interval:     interval,
+		Buckets:      make([]time.Time, 0),
+		Repositories: make([]cu.RepoDownloadTimeline, 0, len(statsList)),
+	}
+
+	var first, last time.Time
+	for _, stats := range statsList {
+		for _, release := range stats.Releases {
+			start := bucketStart(release.CreatedAt, interval)
+			if first.IsZero() || start.Before(first) {
+				first = start
+			}
+			if start.After(last) {
+				last = start
+			}
+		}
+	}
+	index := make(map[time.Time]int)
+	if !first.IsZero() {
+		for start := first; !start.After(last); start = nextBucket(start, interval) {
+			index[start] = len(comparison.Buckets)
+			comparison.Buckets = append(comparison.Buckets, start)
+		}
+	}
+
+	for _, stats := range statsList {
+		timeline := cu.RepoDownloadTimeline{
+			RepoName:       stats.RepoName,
+			TotalDownloads: stats.TotalDownloads,
+			Downloads:      make([]int, len(comparison.Buckets)),
+			Cumulative:     make([]int, len(comparison.Buckets)),
+			Releases:       make([]cu.ReleasePoint, 0, len(stats.Releases)),
+		}
+
+		for _, release := range releasesByDate(stats.Releases) {
+			timeline.Downloads[index[bucketStart(release.CreatedAt, interval)]] += release.TotalDownloads
+			timeline.Releases = append(timeline.Releases, cu.ReleasePoint{
+				TagName:        release.TagName,
+				CreatedAt:      release.CreatedAt,
+				TotalDownloads: release.TotalDownloads,
+			})
+		}
+
+		total := 0
+		for i, downloads := range timeline.Downloads {
+			total += downloads
+			timeline.Cumulative[i] = total
+		}
+		comparison.Repositories = append(comparison.Repositories, timeline)
+	}
+	return comparison
+}
+
+func HandleCompareDownloads(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := requestContext(r)
+	defer cancel()
+
+	repos := r.URL.Query()["repo"]
+	if len(repos) < 2 {
+		http.Error(w, "At least two repository URLs are required", http.StatusBadRequest)
+		return
+	}
+
+	interval := r.URL.Query().Get("interval")
+	switch interval {
+	case "":
+		interval = intervalMonthly
+	case intervalDaily, intervalWeekly, intervalMonthly:
+	default:
+		http.Error(w, fmt.Sprintf("unknown interval %q, expected daily, weekly or monthly", interval), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseReleaseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	excludeChecksums := r.URL.Query().Get("exclude_checksums") == "1"
+
+	// Make token optional
+	var config *cu.Config
+	authHeader := r.Header.Get("Authorization")
+	if authHeader != "" {
+		token := strings.TrimPrefix(authHeader, "Bearer ")
+		token = strings.TrimSpace(token)
+		config = &cu.Config{GithubToken: token}
+	}
+
+	refs := make([]repoRef, 0, len(repos))
+	for _, repoURL := range repos {
+		host, owner, repo, err := parseRepoURL(repoURL)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid repository URL: %v", err), http.StatusBadRequest)
+			return
+		}
+		refs = append(refs, repoRef{Owner: owner, Repo: repo, Config: forHost(config, host)})
+	}
+
+	results := pool.Map(ctx, concurrency, refs, func(ctx context.Context, ref repoRef) (*cu.DownloadStats, error) {
+		stats, _, err := fetchDownloadStats(ctx, r, ref)
+		return stats, err
+	})
+
+	statsList := make([]*cu.DownloadStats, 0, len(refs))
+	var repoErrors []cu.RepoError
+	failed := -1
+	for i, res := range results {
+		if res.Err != nil {
+			if failed < 0 {
+				failed = i
+			}
+			repoErrors = append(repoErrors, cu.RepoError{RepoName: refs[i].Name(), Error: res.Err.Error()})
+			continue
+		}
+		filterReleases(res.Value, filter)
+		classifyDownloads(res.Value, excludeChecksums)
+		statsList = append(statsList, res.Value)
+	}
+
+	// Partial results are still useful; only fail when nothing was fetched
+	if len(statsList) == 0 {
+		writeFetchError(w, refs[failed].Config, results[failed].Err)
+		return
+	}
+
+	comparison := compareDownloads(statsList, interval)
+	comparison.Errors = repoErrors
+
+	writeRateLimitHeaders(w, refsConfig(refs))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comparison)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/github"
+)
+
+func TestCompareDownloads(t *testing.T) {
+	month := func(m time.Month) time.Time { return time.Date(2024, m, 15, 0, 0, 0, 0, time.UTC) }
+	statsList := []*cu.DownloadStats{
+		{RepoName: "keploy/keploy", TotalDownloads: 30, Releases: []cu.ReleaseDownloadStats{
+			{TagName: "v2", CreatedAt: month(4), TotalDownloads: 20},
+			{TagName: "v1", CreatedAt: month(1), TotalDownloads: 10},
+		}},
+		{RepoName: "other/cli", TotalDownloads: 5, Releases: []cu.ReleaseDownloadStats{
+			{TagName: "v0.1", CreatedAt: month(2), TotalDownloads: 5},
+		}},
+	}
+
+	comparison := compareDownloads(statsList, intervalMonthly)
+
+	if len(comparison.Buckets) != 4 || !comparison.Buckets[0].Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Expected 4 monthly buckets from January, got %v", comparison.Buckets)
+	}
+
+	expected := map[string][2][]int{
+		"keploy/keploy": {{10, 0, 0, 20}, {10, 10, 10, 30}},
+		"other/cli":     {{0, 5, 0, 0}, {0, 5, 5, 5}},
+	}
+	for _, timeline := range comparison.Repositories {
+		want := expected[timeline.RepoName]
+		for i := range comparison.Buckets {
+			if timeline.Downloads[i] != want[0][i] || timeline.Cumulative[i] != want[1][i] {
+				t.Errorf("Unexpected %s bucket %d: downloads %d, cumulative %d", timeline.RepoName, i, timeline.Downloads[i], timeline.Cumulative[i])
+			}
+		}
+	}
+	if releases := comparison.Repositories[0].Releases; releases[0].TagName != "v1" {
+		t.Errorf("Expected releases oldest first, got %+v", releases)
+	}
+}
+
+func TestCompareDownloads_Backport(t *testing.T) {
+	month := func(m time.Month) time.Time { return time.Date(2024, m, 15, 0, 0, 0, 0, time.UTC) }
+	stats := calculateDownloadStats([]cu.Release{
+		{TagName: "v2.0.0", CreatedAt: month(1), Assets: []cu.ReleaseAsset{{Name: "v2.tar.gz", DownloadCount: 20}}},
+		{TagName: "v1.9.1", CreatedAt: month(3), Assets: []cu.ReleaseAsset{{Name: "v1.tar.gz", DownloadCount: 5}}},
+	})
+
+	comparison := compareDownloads([]*cu.DownloadStats{stats}, intervalMonthly)
+
+	timeline := comparison.Repositories[0]
+	if timeline.Releases[0].TagName != "v2.0.0" || timeline.Releases[1].TagName != "v1.9.1" {
+		t.Errorf("Expected releases in the order they were created, got %+v", timeline.Releases)
+	}
+	if want := []int{20, 20, 25}; !slices.Equal(timeline.Cumulative, want) {
+		t.Errorf("Expected cumulative downloads %v, got %v", want, timeline.Cumulative)
+	}
+}
+
+func TestHandleCompareDownloads(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/keploy/keploy/releases":
+			w.Write([]byte(`[{"tag_name":"v1.0.0","created_at":"2024-03-04T00:00:00Z","assets":[{"name":"keploy_linux_amd64.tar.gz","download_count":12},{"name":"checksums.txt","download_count":3}]}]`))
+		case "/repos/other/cli/releases":
+			w.Write([]byte(`[{"tag_name":"v0.1.0","created_at":"2024-03-13T00:00:00Z","assets":[{"name":"cli_linux_amd64.tar.gz","download_count":8}]}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedRepos  int
+		expectedErrors int
+	}{
+		{"one repository", "repo=https://github.com/keploy/keploy", http.StatusBadRequest, 0, 0},
+		{"unknown interval", "repo=https://github.com/keploy/keploy&repo=https://github.com/other/cli&interval=yearly", http.StatusBadRequest, 0, 0},
+		{"two repositories", "repo=https://github.com/keploy/keploy&repo=https://github.com/other/cli&interval=weekly&exclude_checksums=1", http.StatusOK, 2, 0},
+		{"partial results", "repo=https://github.com/keploy/keploy&repo=https://github.com/missing/repo", http.StatusOK, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			HandleCompareDownloads(rr, httptest.NewRequest(http.MethodGet, "/compare-downloads?"+tt.query, nil))
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status code %v, got %v: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var comparison cu.DownloadComparison
+			if err := json.NewDecoder(rr.Body).Decode(&comparison); err != nil {
+				t.Fatalf("Error decoding response: %v", err)
+			}
+			if len(comparison.Repositories) != tt.expectedRepos || len(comparison.Errors) != tt.expectedErrors {
+				t.Errorf("Expected %d repositories and %d errors, got %d and %d", tt.expectedRepos, tt.expectedErrors, len(comparison.Repositories), len(comparison.Errors))
+			}
+			if tt.expectedRepos == 2 {
+				if len(comparison.Buckets) != 2 || comparison.Repositories[0].TotalDownloads != 12 {
+					t.Errorf("Unexpected comparison %+v", comparison)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleCompareDownloads_RateLimitHeaders(t *testing.T) {
+	fakeHost := func(remaining string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Limit", "5000")
+			w.Header().Set("X-RateLimit-Remaining", remaining)
+			w.Header().Set("X-RateLimit-Reset", "1893456000")
+			w.Write([]byte(`[]`))
+		}))
+	}
+	public, enterprise := fakeHost("4999"), fakeHost("42")
+	defer public.Close()
+	defer enterprise.Close()
+	useFakeGitHub(t, public)
+	SetHostClient("ghe.example.com", github.NewClient(github.WithBaseURL(enterprise.URL)))
+	t.Cleanup(func() { delete(hostClients, "ghe.example.com") })
+
+	tests := []struct {
+		name              string
+		query             string
+		expectedRemaining string
+	}{
+		{"enterprise", "repo=https://ghe.example.com/platform/api&repo=https://ghe.example.com/platform/web", "42"},
+		{"public", "repo=https://github.com/keploy/keploy&repo=https://github.com/keploy/gitstats", "4999"},
+		{"mixed hosts", "repo=https://github.com/keploy/keploy&repo=https://ghe.example.com/platform/api", ""},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		HandleCompareDownloads(rr, httptest.NewRequest(http.MethodGet, "/compare-downloads?"+tt.query, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected status code %v, got %v: %s", tt.name, http.StatusOK, rr.Code, rr.Body.String())
+		}
+		if got := rr.Header().Get("X-GitHub-RateLimit-Remaining"); got != tt.expectedRemaining {
+			t.Errorf("%s: expected remaining budget %q, got %q", tt.name, tt.expectedRemaining, got)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+var (
+	// concurrency bounds how many repositories a single request fetches in
+	// parallel.
+	concurrency = 8
+
+	// requestTimeout bounds the GitHub work done for a single request;
+	// endpointTimeouts overrides it per endpoint path.
+	requestTimeout   = 2 * time.Minute
+	endpointTimeouts = map[string]time.Duration{}
+
+	// precomputedMaxAge is how old a collected result may be and still be
+	// served; zero serves results of any age.
+	precomputedMaxAge = 48 * time.Hour
+
+	// adminToken authorizes changes to the tracked targets; when empty
+	// they cannot be changed through the API.
+	adminToken string
+)
+
+// Configure applies the server-wide settings to the handlers.
+func Configure(config cu.ServerConfig) {
+	if config.Concurrency > 0 {
+		concurrency = config.Concurrency
+	}
+	if config.RequestTimeout > 0 {
+		requestTimeout = config.RequestTimeout
+	}
+	if config.EndpointTimeouts != nil {
+		endpointTimeouts = config.EndpointTimeouts
+	}
+	precomputedMaxAge = config.PrecomputedMaxAge
+	adminToken = config.AdminToken
+}
+
+// requestContext derives the context for the GitHub fetches of r. It is
+// cancelled when the client goes away or the endpoint's deadline passes,
+// whichever comes first, so abandoned requests stop consuming API quota.
+func requestContext(r *http.Request) (context.Context, context.CancelFunc) {
+	timeout := requestTimeout
+	if t, ok := endpointTimeouts[r.URL.Path]; ok {
+		timeout = t
+	}
+	if timeout <= 0 {
+		return context.WithCancel(r.Context())
+	}
+	return context.WithTimeout(r.Context(), timeout)
+}
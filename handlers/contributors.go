@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/keploy/gitstats/bots"
+	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/mailmap"
+	"github.com/keploy/gitstats/pool"
+)
+
+// Bot modes, selected with the bots query parameter of /org-contributors
+// and /active-contributors
+const (
+	botsInclude = "include"
+	botsExclude = "exclude"
+	botsOnly    = "only"
+)
+
+// contributorFilter selects the contributors that are counted
+type contributorFilter struct {
+	// Anon counts commit emails without a GitHub account
+	Anon bool
+	// Bots includes bots, excludes them or counts nothing but bots
+	Bots string
+}
+
+// defaultContributorFilter counts every contributor with a GitHub login
+var defaultContributorFilter = contributorFilter{Bots: botsInclude}
+
+func parseContributorFilter(query url.Values) (contributorFilter, error) {
+	filter := contributorFilter{Anon: query.Get("anon") == "1", Bots: botsInclude}
+
+	switch mode := query.Get("bots"); mode {
+	case "", botsInclude:
+	case botsExclude, botsOnly:
+		filter.Bots = mode
+	default:
+		return filter, fmt.Errorf("unknown bots %q, expected include, exclude or only", mode)
+	}
+	return filter, nil
+}
+
+// counts reports whether a contributor passes the bot mode
+func (f contributorFilter) counts(bot bool) bool {
+	switch f.Bots {
+	case botsExclude:
+		return !bot
+	case botsOnly:
+		return bot
+	default:
+		return true
+	}
+}
+
+// botClassifier tells bots from people. The built-in patterns always
+// compile.
+var botClassifier, _ = bots.NewClassifier(bots.Rules{})
+
+// SetBotClassifier replaces the classifier used by the contributor
+// endpoints, e.g. to list CI accounts.
+func SetBotClassifier(c *bots.Classifier) {
+	botClassifier = c
+}
+
+// isBot classifies an author by login, or by name for commit emails
+// without a GitHub account
+func isBot(login, name, userType string) bool {
+	return botClassifier.IsBot(cmp.Or(login, name), userType)
+}
+
+// summarizeBots orders bot activity by contributions
+func summarizeBots(activity map[string]*cu.BotActivity) cu.BotSummary {
+	summary := cu.BotSummary{Bots: make([]cu.BotActivity, 0, len(activity))}
+	for _, bot := range activity {
+		summary.Contributions += bot.Contributions
+		summary.Bots = append(summary.Bots, *bot)
+	}
+	summary.Accounts = len(summary.Bots)
+	slices.SortFunc(summary.Bots, func(a, b cu.BotActivity) int {
+		return cmp.Or(
+			cmp.Compare(b.Contributions, a.Contributions),
+			cmp.Compare(a.Login, b.Login),
+		)
+	})
+	return summary
+}
+
+// identities merges the logins and commit emails of one person so they are
+// counted once; nil merges nothing.
+var identities *mailmap.Mailmap
+
+// SetMailmap sets the aliases used to merge contributor identities.
+func SetMailmap(m *mailmap.Mailmap) {
+	identities = m
+}
+
+// contributorKey returns the canonical identity of a contributor
+func contributorKey(contributor cu.Contributor) string {
+	return identities.Resolve(mailmap.Identity{
+		Login: contributor.Login,
+		Name:  contributor.Name,
+		Email: contributor.Email,
+	})
+}
+
+// getOrgContributors counts the contributors of every repository of an
+// organization that pass filter: with Anon, commit emails without a GitHub
+// account count as contributors too. Identities the mailmap merges count
+// once, both in a repository and in the organization. Bots are summarized
+// separately whether or not they are counted.
+func getOrgContributors(ctx context.Context, org string, config *cu.Config, filter contributorFilter) (*cu.OrganizationStats, error) {
+	client := clientFor(config)
+
+	repos, err := client.ListOrgRepos(ctx, org, "")
+	if err != nil {
+		return nil, err
+	}
+
+	results := pool.Map(ctx, concurrency, repos, func(ctx context.Context, repo cu.Repository) ([]cu.Contributor, error) {
+		return client.ListContributors(ctx, org, repo.Name, filter.Anon)
+	})
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stats := &cu.OrganizationStats{
+		OrgName:      org,
+		TotalRepos:   len(repos),
+		Repositories: make([]cu.RepoContributors, 0, len(repos)),
+	}
+	contributors := make(map[string]*cu.OrgContributor)
+	botActivity := make(map[string]*cu.BotActivity)
+	for i, res := range results {
+		if res.Err != nil {
+			stats.Errors = append(stats.Errors, cu.RepoError{
+				RepoName: fmt.Sprintf("%s/%s", org, repos[i].Name),
+				Error:    res.Err.Error(),
+			})
+			continue
+		}
+
+		repoStats := cu.RepoContributors{
+			RepoName: fmt.Sprintf("%s/%s", org, repos[i].Name),
+			Stars:    repos[i].StargazersCount,
+			Archived: repos[i].Archived,
+			Fork:     repos[i].Fork,
+		}
+		seen := make(map[string]struct{}, len(res.Value))
+		seenBots := make(map[string]struct{})
+		for _, contributor := range res.Value {
+			key := contributorKey(contributor)
+			bot := isBot(contributor.Login, contributor.Name, contributor.Type)
+			if bot {
+				activity, ok := botActivity[key]
+				if !ok {
+					activity = &cu.BotActivity{Login: key}
+					botActivity[key] = activity
+				}
+				activity.Contributions += contributor.Contributions
+				if _, ok := seenBots[key]; !ok {
+					seenBots[key] = struct{}{}
+					activity.Repos++
+				}
+			}
+			if !filter.counts(bot) {
+				continue
+			}
+
+			repoStats.Commits += contributor.Contributions
+			total, ok := contributors[key]
+			if !ok {
+				total = &cu.OrgContributor{Login: key, Anonymous: true}
+				contributors[key] = total
+			}
+			if bot {
+				total.Bot = true
+			}
+			// One identity with a login makes the merged contributor known
+			if contributor.Login != "" {
+				total.Anonymous = false
+			}
+			total.Contributions += contributor.Contributions
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				total.Repos++
+			}
+		}
+		repoStats.Contributors = len(seen)
+		stats.Repositories = append(stats.Repositories, repoStats)
+	}
+
+	stats.TotalContributors = len(contributors)
+	stats.Contributors = rankContributors(contributors)
+	stats.Bots = summarizeBots(botActivity)
+	slices.SortFunc(stats.Repositories, func(a, b cu.RepoContributors) int {
+		return cmp.Compare(a.RepoName, b.RepoName)
+	})
+
+	return stats, nil
+}
+
+// rankContributors orders contributors by the number of repositories they
+// touched, then by their contributions
+func rankContributors(contributors map[string]*cu.OrgContributor) []cu.OrgContributor {
+	ranked := make([]cu.OrgContributor, 0, len(contributors))
+	for _, contributor := range contributors {
+		ranked = append(ranked, *contributor)
+	}
+	slices.SortFunc(ranked, func(a, b cu.OrgContributor) int {
+		return cmp.Or(
+			cmp.Compare(b.Repos, a.Repos),
+			cmp.Compare(b.Contributions, a.Contributions),
+			cmp.Compare(a.Login, b.Login),
+		)
+	})
+	return ranked
+}
@@ -0,0 +1,399 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keploy/gitstats/bots"
+	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/mailmap"
+)
+
+// useMailmap merges identities with the given alias file for the duration
+// of the test.
+func useMailmap(t *testing.T, aliases string) {
+	t.Helper()
+	m, err := mailmap.Parse(strings.NewReader(aliases))
+	if err != nil {
+		t.Fatalf("Error parsing mailmap: %v", err)
+	}
+	previous := identities
+	SetMailmap(m)
+	t.Cleanup(func() { SetMailmap(previous) })
+}
+
+// fakeOrgServer serves the repositories of the keploy organization and the
+// contributors of each, splitting contributor lists into pages of two.
+// Contributors without a login are anonymous and only listed with anon=1.
+func fakeOrgServer(t *testing.T, repos string, contributors map[string][]cu.Contributor) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/orgs/keploy/repos" {
+			w.Write([]byte(repos))
+			return
+		}
+
+		var repo string
+		if _, err := fmt.Sscanf(r.URL.Path, "/repos/keploy/%s", &repo); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		repo = repo[:len(repo)-len("/contributors")]
+		all, ok := contributors[repo]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		var list []cu.Contributor
+		for _, contributor := range all {
+			if contributor.Login != "" || r.URL.Query().Get("anon") == "1" {
+				list = append(list, contributor)
+			}
+		}
+
+		page := 1
+		fmt.Sscanf(r.URL.Query().Get("page"), "%d", &page)
+		end := min(page*2, len(list))
+		if end < len(list) {
+			query := r.URL.Query()
+			query.Set("page", fmt.Sprint(page+1))
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?%s>; rel="next"`, r.Host, r.URL.Path, query.Encode()))
+		}
+		json.NewEncoder(w).Encode(list[(page-1)*2 : end])
+	}))
+	t.Cleanup(server.Close)
+	useFakeGitHub(t, server)
+}
+
+func TestHandleOrgContributors_Breakdown(t *testing.T) {
+	fakeOrgServer(t, `[
+		{"name":"keploy","stargazers_count":100},
+		{"name":"docs","stargazers_count":5,"archived":true},
+		{"name":"fork","stargazers_count":1,"fork":true},
+		{"name":"missing"}
+	]`, map[string][]cu.Contributor{
+		"keploy": {
+			{Login: "alice", Contributions: 50},
+			{Login: "bob", Contributions: 20},
+			{Login: "carol", Contributions: 10},
+			{Login: "dave", Contributions: 5},
+			{Login: "erin", Contributions: 1},
+		},
+		"docs": {
+			{Login: "bob", Contributions: 3},
+			{Login: "erin", Contributions: 30},
+		},
+		"fork": {
+			{Login: "erin", Contributions: 2},
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	HandleOrgContributors(rr, httptest.NewRequest(http.MethodGet, "/org-contributors?org=keploy", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var stats cu.OrganizationStats
+	if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	if stats.TotalRepos != 4 || stats.TotalContributors != 5 {
+		t.Errorf("Expected 4 repos and 5 contributors, got %d and %d", stats.TotalRepos, stats.TotalContributors)
+	}
+	if len(stats.Errors) != 1 || stats.Errors[0].RepoName != "keploy/missing" {
+		t.Errorf("Expected keploy/missing to fail, got %+v", stats.Errors)
+	}
+
+	expectedRepos := []cu.RepoContributors{
+		{RepoName: "keploy/docs", Contributors: 2, Commits: 33, Stars: 5, Archived: true},
+		{RepoName: "keploy/fork", Contributors: 1, Commits: 2, Stars: 1, Fork: true},
+		{RepoName: "keploy/keploy", Contributors: 5, Commits: 86, Stars: 100},
+	}
+	if !reflect.DeepEqual(stats.Repositories, expectedRepos) {
+		t.Errorf("Expected repositories %+v, got %+v", expectedRepos, stats.Repositories)
+	}
+
+	expectedContributors := []cu.OrgContributor{
+		{Login: "erin", Repos: 3, Contributions: 33},
+		{Login: "bob", Repos: 2, Contributions: 23},
+		{Login: "alice", Repos: 1, Contributions: 50},
+		{Login: "carol", Repos: 1, Contributions: 10},
+		{Login: "dave", Repos: 1, Contributions: 5},
+	}
+	if !reflect.DeepEqual(stats.Contributors, expectedContributors) {
+		t.Errorf("Expected contributors %+v, got %+v", expectedContributors, stats.Contributors)
+	}
+}
+
+func TestHandleOrgContributors_Anonymous(t *testing.T) {
+	useMailmap(t, `
+@alice <alice@home.example.com> <alice@work.example.com>
+Bob <bob@example.com> <bob@laptop.local>
+`)
+	fakeOrgServer(t, `[{"name":"keploy"},{"name":"docs"}]`, map[string][]cu.Contributor{
+		"keploy": {
+			{Login: "alice", Contributions: 10},
+			{Type: "Anonymous", Name: "Alice", Email: "alice@work.example.com", Contributions: 4},
+			{Type: "Anonymous", Name: "Bob", Email: "bob@example.com", Contributions: 3},
+			{Type: "Anonymous", Name: "Bob", Email: "BOB@laptop.local", Contributions: 2},
+			{Type: "Anonymous", Name: "Carol", Email: "carol@example.com", Contributions: 1},
+		},
+		"docs": {
+			{Type: "Anonymous", Name: "Alice", Email: "alice@home.example.com", Contributions: 6},
+			{Type: "Anonymous", Name: "Bob", Email: "bob@laptop.local", Contributions: 1},
+		},
+	})
+
+	tests := []struct {
+		query                string
+		expectedRepos        []cu.RepoContributors
+		expectedContributors []cu.OrgContributor
+	}{
+		{
+			query: "/org-contributors?org=keploy",
+			expectedRepos: []cu.RepoContributors{
+				{RepoName: "keploy/docs"},
+				{RepoName: "keploy/keploy", Contributors: 1, Commits: 10},
+			},
+			expectedContributors: []cu.OrgContributor{
+				{Login: "alice", Repos: 1, Contributions: 10},
+			},
+		},
+		{
+			query: "/org-contributors?org=keploy&anon=1",
+			expectedRepos: []cu.RepoContributors{
+				{RepoName: "keploy/docs", Contributors: 2, Commits: 7},
+				{RepoName: "keploy/keploy", Contributors: 3, Commits: 20},
+			},
+			expectedContributors: []cu.OrgContributor{
+				{Login: "alice", Repos: 2, Contributions: 20},
+				{Login: "Bob", Repos: 2, Contributions: 6, Anonymous: true},
+				{Login: "carol@example.com", Repos: 1, Contributions: 1, Anonymous: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		HandleOrgContributors(rr, httptest.NewRequest(http.MethodGet, tt.query, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected status code %v, got %v: %s", tt.query, http.StatusOK, rr.Code, rr.Body.String())
+		}
+
+		var stats cu.OrganizationStats
+		if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+			t.Fatalf("Error decoding response: %v", err)
+		}
+		if stats.TotalContributors != len(tt.expectedContributors) {
+			t.Errorf("%s: expected %d contributors, got %d", tt.query, len(tt.expectedContributors), stats.TotalContributors)
+		}
+		if !reflect.DeepEqual(stats.Repositories, tt.expectedRepos) {
+			t.Errorf("%s: expected repositories %+v, got %+v", tt.query, tt.expectedRepos, stats.Repositories)
+		}
+		if !reflect.DeepEqual(stats.Contributors, tt.expectedContributors) {
+			t.Errorf("%s: expected contributors %+v, got %+v", tt.query, tt.expectedContributors, stats.Contributors)
+		}
+	}
+}
+
+func TestProcessCommits_Anonymous(t *testing.T) {
+	useMailmap(t, "@alice <alice@work.example.com>\n")
+
+	commit := func(login, email string, day int) cu.Commit {
+		var c cu.Commit
+		c.Author.Login = login
+		c.Commit.Author.Email = email
+		c.Commit.Author.Date = time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)
+		return c
+	}
+	commits := []cu.Commit{
+		commit("alice", "alice@example.com", 1),
+		commit("", "alice@work.example.com", 3),
+		commit("", "carol@example.com", 2),
+		commit("member", "member@example.com", 2),
+	}
+	members := map[string]struct{}{"member": {}}
+
+	stats := make(map[string]*cu.ActiveContributor)
+	processCommits(commits, members, stats, contributorFilter{Bots: botsInclude})
+	if len(stats) != 1 || stats["alice"].Contributions != 1 {
+		t.Errorf("Expected only alice's commit with a login, got %+v", stats)
+	}
+
+	stats = make(map[string]*cu.ActiveContributor)
+	processCommits(commits, members, stats, contributorFilter{Anon: true, Bots: botsInclude})
+	if len(stats) != 2 {
+		t.Fatalf("Expected alice and carol, got %+v", stats)
+	}
+	alice := stats["alice"]
+	if alice.Contributions != 2 || alice.Anonymous || !alice.LastActiveDate.Equal(commits[1].Commit.Author.Date) {
+		t.Errorf("Expected alice's commits merged across emails, got %+v", alice)
+	}
+	if carol := stats["carol@example.com"]; carol == nil || !carol.Anonymous {
+		t.Errorf("Expected carol as an anonymous contributor, got %+v", carol)
+	}
+}
+
+// useBotRules classifies bots with the given rules for the duration of the
+// test.
+func useBotRules(t *testing.T, rules bots.Rules) {
+	t.Helper()
+	c, err := bots.NewClassifier(rules)
+	if err != nil {
+		t.Fatalf("Error creating bot classifier: %v", err)
+	}
+	previous := botClassifier
+	SetBotClassifier(c)
+	t.Cleanup(func() { SetBotClassifier(previous) })
+}
+
+func TestParseContributorFilter(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected contributorFilter
+		hasError bool
+	}{
+		{"", defaultContributorFilter, false},
+		{"anon=1&bots=exclude", contributorFilter{Anon: true, Bots: botsExclude}, false},
+		{"bots=only", contributorFilter{Bots: botsOnly}, false},
+		{"bots=none", contributorFilter{}, true},
+	}
+
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		filter, err := parseContributorFilter(query)
+		if (err != nil) != tt.hasError {
+			t.Errorf("%q: expected error %v, got %v", tt.query, tt.hasError, err)
+			continue
+		}
+		if !tt.hasError && filter != tt.expected {
+			t.Errorf("%q: expected %+v, got %+v", tt.query, tt.expected, filter)
+		}
+	}
+}
+
+func TestHandleOrgContributors_Bots(t *testing.T) {
+	useBotRules(t, bots.Rules{Bots: []string{"keploy-ci"}})
+	fakeOrgServer(t, `[{"name":"keploy"},{"name":"docs"}]`, map[string][]cu.Contributor{
+		"keploy": {
+			{Login: "dependabot[bot]", Type: "Bot", Contributions: 40},
+			{Login: "alice", Type: "User", Contributions: 10},
+			{Login: "keploy-ci", Type: "User", Contributions: 5},
+		},
+		"docs": {
+			{Login: "dependabot[bot]", Type: "Bot", Contributions: 2},
+			{Login: "bob", Type: "User", Contributions: 3},
+		},
+	})
+
+	expectedBots := cu.BotSummary{
+		Accounts:      2,
+		Contributions: 47,
+		Bots: []cu.BotActivity{
+			{Login: "dependabot[bot]", Contributions: 42, Repos: 2},
+			{Login: "keploy-ci", Contributions: 5, Repos: 1},
+		},
+	}
+
+	tests := []struct {
+		query                string
+		expectedContributors []string
+		expectedCommits      int
+	}{
+		{"/org-contributors?org=keploy", []string{"dependabot[bot]", "alice", "keploy-ci", "bob"}, 60},
+		{"/org-contributors?org=keploy&bots=exclude", []string{"alice", "bob"}, 13},
+		{"/org-contributors?org=keploy&bots=only", []string{"dependabot[bot]", "keploy-ci"}, 47},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		HandleOrgContributors(rr, httptest.NewRequest(http.MethodGet, tt.query, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected status code %v, got %v: %s", tt.query, http.StatusOK, rr.Code, rr.Body.String())
+		}
+
+		var stats cu.OrganizationStats
+		if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+			t.Fatalf("Error decoding response: %v", err)
+		}
+
+		var logins []string
+		for _, contributor := range stats.Contributors {
+			logins = append(logins, contributor.Login)
+			if isBot := contributor.Login != "alice" && contributor.Login != "bob"; contributor.Bot != isBot {
+				t.Errorf("%s: expected %s to be marked bot=%v", tt.query, contributor.Login, isBot)
+			}
+		}
+		if !reflect.DeepEqual(logins, tt.expectedContributors) {
+			t.Errorf("%s: expected contributors %v, got %v", tt.query, tt.expectedContributors, logins)
+		}
+		if stats.TotalContributors != len(tt.expectedContributors) {
+			t.Errorf("%s: expected %d contributors, got %d", tt.query, len(tt.expectedContributors), stats.TotalContributors)
+		}
+		commits := 0
+		for _, repo := range stats.Repositories {
+			commits += repo.Commits
+		}
+		if commits != tt.expectedCommits {
+			t.Errorf("%s: expected %d commits, got %d", tt.query, tt.expectedCommits, commits)
+		}
+		if !reflect.DeepEqual(stats.Bots, expectedBots) {
+			t.Errorf("%s: expected bot summary %+v, got %+v", tt.query, expectedBots, stats.Bots)
+		}
+	}
+}
+
+func TestPrepareResponse_Bots(t *testing.T) {
+	commit := func(login, userType, name string) cu.Commit {
+		var c cu.Commit
+		c.Author.Login, c.Author.Type = login, userType
+		c.Commit.Author.Name = name
+		c.Commit.Author.Date = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		return c
+	}
+	commits := []cu.Commit{
+		commit("renovate[bot]", "Bot", "renovate[bot]"),
+		commit("renovate[bot]", "Bot", "renovate[bot]"),
+		commit("renovate[bot]", "Bot", "renovate[bot]"),
+		commit("github-actions", "", "GitHub Actions"),
+		commit("", "", "dependabot[bot]"),
+		commit("alice", "User", "Alice"),
+	}
+
+	tests := []struct {
+		filter   contributorFilter
+		expected []string
+	}{
+		{contributorFilter{Bots: botsInclude}, []string{"alice", "github-actions", "renovate[bot]"}},
+		{contributorFilter{Bots: botsExclude}, []string{"alice"}},
+		{contributorFilter{Anon: true, Bots: botsOnly}, []string{"dependabot[bot]", "github-actions", "renovate[bot]"}},
+	}
+
+	for _, tt := range tests {
+		stats := make(map[string]*cu.ActiveContributor)
+		processCommits(commits, nil, stats, tt.filter)
+		response := prepareResponse(stats, "keploy", "keploy", tt.filter)
+
+		var logins []string
+		for _, contributor := range response.ActiveContributors {
+			logins = append(logins, contributor.Login)
+		}
+		sort.Strings(logins)
+		if !reflect.DeepEqual(logins, tt.expected) {
+			t.Errorf("%+v: expected contributors %v, got %v", tt.filter, tt.expected, logins)
+		}
+		if response.Bots.Bots[0].Login != "renovate[bot]" || response.Bots.Bots[0].Contributions != 3 {
+			t.Errorf("%+v: expected renovate[bot] to lead the bot summary, got %+v", tt.filter, response.Bots)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"cmp"
+	"fmt"
+	"time"
+
+	"github.com/keploy/gitstats/assets"
+	cu "github.com/keploy/gitstats/common"
+)
+
+// assetClassifier derives OS, architecture and format of release assets. The
+// built-in rules always compile.
+var assetClassifier, _ = assets.NewClassifier(nil)
+
+// SetAssetClassifier replaces the classifier used for /repo-stats, e.g. to
+// add custom rules.
+func SetAssetClassifier(c *assets.Classifier) {
+	assetClassifier = c
+}
+
+// Velocity intervals, selected with the velocity query parameter of
+// /repo-stats
+const (
+	intervalDaily   = "daily"
+	intervalWeekly  = "weekly"
+	intervalMonthly = "monthly"
+)
+
+// parseVelocityInterval validates the velocity query parameter. An empty
+// value means no trends were requested.
+func parseVelocityInterval(value string) (string, error) {
+	switch value {
+	case "", intervalDaily, intervalWeekly:
+		return value, nil
+	default:
+		return "", fmt.Errorf("unknown velocity %q, expected daily or weekly", value)
+	}
+}
+
+// classifyDownloads annotates every asset with its classification and sums
+// the downloads per OS, architecture and format. With excludeVerification,
+// checksum and signature files are dropped and the totals recomputed
+// without them.
+func classifyDownloads(stats *cu.DownloadStats, excludeVerification bool) {
+	breakdown := &cu.DownloadBreakdown{
+		ByOS:     make(map[string]int),
+		ByArch:   make(map[string]int),
+		ByFormat: make(map[string]int),
+	}
+
+	stats.TotalDownloads = 0
+	for i := range stats.Releases {
+		release := &stats.Releases[i]
+		assetList := make([]cu.AssetStats, 0, len(release.Assets))
+		release.TotalDownloads = 0
+
+		for _, asset := range release.Assets {
+			class := assetClassifier.Classify(asset.Name)
+			if excludeVerification && class.IsVerification() {
+				continue
+			}
+			asset.OS, asset.Arch, asset.Format = class.OS, class.Arch, class.Format
+			assetList = append(assetList, asset)
+			release.TotalDownloads += asset.DownloadCount
+
+			breakdown.ByOS[cmp.Or(class.OS, assets.Unknown)] += asset.DownloadCount
+			breakdown.ByArch[cmp.Or(class.Arch, assets.Unknown)] += asset.DownloadCount
+			breakdown.ByFormat[cmp.Or(class.Format, assets.Unknown)] += asset.DownloadCount
+		}
+
+		release.Assets = assetList
+		stats.TotalDownloads += release.TotalDownloads
+	}
+	stats.Breakdown = breakdown
+}
+
+// bucketStart returns the start of the interval t falls in. Intervals are
+// aligned to UTC days, weeks start on Monday.
+func bucketStart(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case intervalWeekly:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case intervalMonthly:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day
+}
+
+// nextBucket returns the start of the interval following the one starting
+// at start.
+func nextBucket(start time.Time, interval string) time.Time {
+	switch interval {
+	case intervalWeekly:
+		return start.AddDate(0, 0, 7)
+	case intervalMonthly:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// downloadCounts holds the cumulative downloads of every release and asset
+// as of the last snapshot taken in the interval starting at start
+type downloadCounts struct {
+	start    time.Time
+	releases map[string]int
+	assets   map[string]map[string]int
+}
+
+// bucketSnapshots reduces time ordered snapshots to one set of counts per
+// interval, keeping the latest snapshot of each.
+func bucketSnapshots(snapshotList []cu.Snapshot, interval string) []downloadCounts {
+	var buckets []downloadCounts
+	for _, snapshot := range snapshotList {
+		if snapshot.Downloads == nil {
+			continue
+		}
+
+		counts := downloadCounts{
+			start:    bucketStart(snapshot.Time, interval),
+			releases: make(map[string]int),
+			assets:   make(map[string]map[string]int),
+		}
+		for _, release := range snapshot.Downloads.Releases {
+			counts.releases[release.TagName] = release.TotalDownloads
+			assets := make(map[string]int, len(release.Assets))
+			for _, asset := range release.Assets {
+				assets[asset.Name] = asset.DownloadCount
+			}
+			counts.assets[release.TagName] = assets
+		}
+
+		if n := len(buckets); n > 0 && buckets[n-1].start.Equal(counts.start) {
+			buckets[n-1] = counts
+			continue
+		}
+		buckets = append(buckets, counts)
+	}
+	return buckets
+}
+
+// velocity turns cumulative counts into downloads per interval. Each point
+// holds the downloads since the previous interval's last snapshot, so the
+// first interval only serves as a baseline. Counts that shrink, for example
+// because an asset was replaced, contribute zero.
+func velocity(buckets []downloadCounts, count func(downloadCounts) int) []cu.VelocityPoint {
+	points := make([]cu.VelocityPoint, 0, max(len(buckets)-1, 0))
+	for i := 1; i < len(buckets); i++ {
+		points = append(points, cu.VelocityPoint{
+			Start:     buckets[i].start,
+			Downloads: max(count(buckets[i])-count(buckets[i-1]), 0),
+		})
+	}
+	return points
+}
+
+// downloadTrends computes velocities, the latest release's share and the
+// migration between releases published one after the other. Velocities
+// keep the order of stats; the latest release and migrations follow the
+// creation dates.
+func downloadTrends(stats *cu.DownloadStats, snapshotList []cu.Snapshot, interval string) *cu.DownloadTrends {
+	trends := &cu.DownloadTrends{
+		Interval:   interval,
+		Releases:   make([]cu.ReleaseVelocity, 0, len(stats.Releases)),
+		Migrations: make([]cu.VersionMigration, 0),
+	}
+
+	byDate := releasesByDate(stats.Releases)
+	if n := len(byDate); n > 0 {
+		latest := byDate[n-1]
+		trends.LatestRelease = latest.TagName
+		if stats.TotalDownloads > 0 {
+			trends.LatestReleaseShare = float64(latest.TotalDownloads) / float64(stats.TotalDownloads)
+		}
+	}
+
+	buckets := bucketSnapshots(snapshotList, interval)
+	velocities := make(map[string][]cu.VelocityPoint, len(stats.Releases))
+	for _, release := range stats.Releases {
+		releaseVelocity := cu.ReleaseVelocity{
+			TagName: release.TagName,
+			Velocity: velocity(buckets, func(c downloadCounts) int {
+				return c.releases[release.TagName]
+			}),
+			Assets: make([]cu.AssetVelocity, 0, len(release.Assets)),
+		}
+		for _, asset := range release.Assets {
+			releaseVelocity.Assets = append(releaseVelocity.Assets, cu.AssetVelocity{
+				Name: asset.Name,
+				Velocity: velocity(buckets, func(c downloadCounts) int {
+					return c.assets[release.TagName][asset.Name]
+				}),
+			})
+		}
+		trends.Releases = append(trends.Releases, releaseVelocity)
+		velocities[release.TagName] = releaseVelocity.Velocity
+	}
+
+	// Oldest pair first, so migrations read chronologically
+	for i := 1; i < len(byDate); i++ {
+		from, to := byDate[i-1], byDate[i]
+		trends.Migrations = append(trends.Migrations, versionMigration(
+			from, to, velocities[from.TagName], velocities[to.TagName], interval,
+		))
+	}
+	return trends
+}
+
+// versionMigration compares the velocity of release to with that of its
+// predecessor from. Both series cover the same intervals.
+func versionMigration(from, to cu.ReleaseDownloadStats, fromVelocity, toVelocity []cu.VelocityPoint, interval string) cu.VersionMigration {
+	migration := cu.VersionMigration{
+		From:       from.TagName,
+		To:         to.TagName,
+		ReleasedAt: to.CreatedAt,
+	}
+
+	released := bucketStart(to.CreatedAt, interval)
+	for i, point := range toVelocity {
+		if point.Start.Before(released) || point.Downloads <= fromVelocity[i].Downloads {
+			continue
+		}
+		overtakenAt := point.Start
+		migration.OvertakenAt = &overtakenAt
+		migration.DaysToOvertake = max(overtakenAt.Sub(to.CreatedAt).Hours()/24, 0)
+		break
+	}
+
+	if n := len(toVelocity); n > 0 {
+		if total := toVelocity[n-1].Downloads + fromVelocity[n-1].Downloads; total > 0 {
+			migration.CurrentShare = float64(toVelocity[n-1].Downloads) / float64(total)
+		}
+	}
+	return migration
+}
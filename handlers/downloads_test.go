@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+func TestBucketStart(t *testing.T) {
+	// 2024-03-07 is a Thursday
+	at := time.Date(2024, 3, 7, 15, 30, 0, 0, time.UTC)
+	if got := bucketStart(at, intervalDaily); !got.Equal(time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected daily bucket 2024-03-07, got %v", got)
+	}
+	if got := bucketStart(at, intervalWeekly); !got.Equal(time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected weekly bucket 2024-03-04, got %v", got)
+	}
+}
+
+func downloadSnapshot(at time.Time, counts map[string]int) cu.Snapshot {
+	downloads := &cu.DownloadStats{}
+	for tag, count := range counts {
+		downloads.TotalDownloads += count
+		downloads.Releases = append(downloads.Releases, cu.ReleaseDownloadStats{
+			TagName:        tag,
+			TotalDownloads: count,
+			Assets:         []cu.AssetStats{{Name: tag + ".tar.gz", DownloadCount: count}},
+		})
+	}
+	return cu.Snapshot{RepoName: "keploy/keploy", Time: at, Downloads: downloads}
+}
+
+func TestDownloadTrends(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 12, 0, 0, 0, time.UTC) }
+	snapshotList := []cu.Snapshot{
+		downloadSnapshot(day(1), map[string]int{"v1": 100}),
+		downloadSnapshot(day(2), map[string]int{"v1": 150, "v2": 10}),
+		// A second snapshot on the same day replaces the first
+		downloadSnapshot(day(3).Add(-time.Hour), map[string]int{"v1": 160, "v2": 40}),
+		downloadSnapshot(day(3), map[string]int{"v1": 170, "v2": 90}),
+		downloadSnapshot(day(4), map[string]int{"v1": 180, "v2": 150}),
+	}
+	stats := &cu.DownloadStats{
+		TotalDownloads: 330,
+		Releases: []cu.ReleaseDownloadStats{
+			{TagName: "v2", CreatedAt: day(2).Add(-time.Hour), TotalDownloads: 150, Assets: []cu.AssetStats{{Name: "v2.tar.gz", DownloadCount: 150}}},
+			{TagName: "v1", CreatedAt: day(1).AddDate(0, 0, -10), TotalDownloads: 180, Assets: []cu.AssetStats{{Name: "v1.tar.gz", DownloadCount: 180}}},
+		},
+	}
+
+	trends := downloadTrends(stats, snapshotList, intervalDaily)
+
+	if trends.LatestRelease != "v2" || trends.LatestReleaseShare != 150.0/330.0 {
+		t.Errorf("Unexpected latest release %s with share %v", trends.LatestRelease, trends.LatestReleaseShare)
+	}
+
+	expected := map[string][]int{"v2": {10, 80, 60}, "v1": {50, 20, 10}}
+	for _, release := range trends.Releases {
+		want := expected[release.TagName]
+		if len(release.Velocity) != len(want) {
+			t.Fatalf("Expected %d velocity points for %s, got %d", len(want), release.TagName, len(release.Velocity))
+		}
+		for i, point := range release.Velocity {
+			if point.Downloads != want[i] {
+				t.Errorf("Expected %d downloads for %s on %v, got %d", want[i], release.TagName, point.Start, point.Downloads)
+			}
+		}
+		if len(release.Assets) != 1 || release.Assets[0].Velocity[len(want)-1].Downloads != want[len(want)-1] {
+			t.Errorf("Unexpected asset velocity for %s: %+v", release.TagName, release.Assets)
+		}
+	}
+
+	if len(trends.Migrations) != 1 {
+		t.Fatalf("Expected 1 migration, got %d", len(trends.Migrations))
+	}
+	migration := trends.Migrations[0]
+	if migration.From != "v1" || migration.To != "v2" {
+		t.Errorf("Expected migration from v1 to v2, got %s to %s", migration.From, migration.To)
+	}
+	if migration.OvertakenAt == nil || !migration.OvertakenAt.Equal(bucketStart(day(3), intervalDaily)) {
+		t.Errorf("Expected v2 to overtake v1 on 2024-03-03, got %v", migration.OvertakenAt)
+	}
+	if migration.CurrentShare != 60.0/70.0 {
+		t.Errorf("Expected current share %v, got %v", 60.0/70.0, migration.CurrentShare)
+	}
+}
+
+func TestDownloadTrends_Backport(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 12, 0, 0, 0, time.UTC) }
+	releases := []cu.Release{
+		{TagName: "v1.9.0", CreatedAt: day(1)},
+		{TagName: "v2.0.0", CreatedAt: day(5), Assets: []cu.ReleaseAsset{{Name: "v2.tar.gz", DownloadCount: 30}}},
+		{TagName: "v1.9.1", CreatedAt: day(10), Assets: []cu.ReleaseAsset{{Name: "v1.tar.gz", DownloadCount: 10}}},
+	}
+	stats := calculateDownloadStats(releases)
+	filterReleases(stats, releaseFilter{Sort: sortVersion})
+	if stats.Releases[0].TagName != "v2.0.0" {
+		t.Fatalf("Expected releases by version, got %+v", stats.Releases)
+	}
+
+	trends := downloadTrends(stats, nil, intervalDaily)
+
+	if trends.LatestRelease != "v1.9.1" || trends.LatestReleaseShare != 10.0/40.0 {
+		t.Errorf("Expected the backport to be the latest release, got %s with share %v", trends.LatestRelease, trends.LatestReleaseShare)
+	}
+	var pairs []string
+	for _, migration := range trends.Migrations {
+		pairs = append(pairs, migration.From+">"+migration.To)
+	}
+	if want := []string{"v1.9.0>v2.0.0", "v2.0.0>v1.9.1"}; !slices.Equal(pairs, want) {
+		t.Errorf("Expected migrations %v in release order, got %v", want, pairs)
+	}
+}
+
+func TestHandleRepoStats_InvalidVelocity(t *testing.T) {
+	rr := httptest.NewRecorder()
+	HandleRepoStats(rr, httptest.NewRequest(http.MethodGet, "/repo-stats?repo=https://github.com/keploy/keploy&velocity=hourly", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %v, got %v", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestHandleRepoStats_Velocity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"tag_name":"v1","created_at":"2024-02-01T00:00:00Z","assets":[{"name":"v1.tar.gz","download_count":180}]}]`))
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+
+	s := useStore(t)
+	s.Append(downloadSnapshot(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), map[string]int{"v1": 100}))
+	s.Append(downloadSnapshot(time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), map[string]int{"v1": 180}))
+
+	rr := httptest.NewRecorder()
+	HandleRepoStats(rr, httptest.NewRequest(http.MethodGet, "/repo-stats?repo=https://github.com/keploy/keploy&velocity=weekly", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var stats cu.DownloadStats
+	if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if stats.Trends == nil || stats.Trends.Interval != intervalWeekly || stats.Trends.LatestReleaseShare != 1 {
+		t.Fatalf("Unexpected trends %+v", stats.Trends)
+	}
+	if velocity := stats.Trends.Releases[0].Velocity; len(velocity) != 1 || velocity[0].Downloads != 80 {
+		t.Errorf("Unexpected weekly velocity %+v", velocity)
+	}
+}
+
+func TestHandleRepoStats_Breakdown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"tag_name":"v1","created_at":"2024-02-01T00:00:00Z","assets":[
+			{"name":"keploy_linux_amd64.tar.gz","download_count":50},
+			{"name":"keploy_darwin_arm64.tar.gz","download_count":30},
+			{"name":"keploy_1.0.0_amd64.deb","download_count":15},
+			{"name":"checksums.txt","download_count":5}
+		]}]`))
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedTotal int
+		expectedLinux int
+		expectedOther int
+	}{
+		{"all assets", "", 100, 65, 5},
+		{"without checksums", "&exclude_checksums=1", 95, 65, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			HandleRepoStats(rr, httptest.NewRequest(http.MethodGet, "/repo-stats?repo=https://github.com/keploy/keploy"+tt.query, nil))
+
+			var stats cu.DownloadStats
+			if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+				t.Fatalf("Error decoding response: %v", err)
+			}
+			if stats.TotalDownloads != tt.expectedTotal || stats.Releases[0].TotalDownloads != tt.expectedTotal {
+				t.Errorf("Expected %d downloads, got %d (release %d)", tt.expectedTotal, stats.TotalDownloads, stats.Releases[0].TotalDownloads)
+			}
+			if stats.Breakdown == nil {
+				t.Fatalf("Expected a breakdown")
+			}
+			if got := stats.Breakdown.ByOS["linux"]; got != tt.expectedLinux {
+				t.Errorf("Expected %d linux downloads, got %d", tt.expectedLinux, got)
+			}
+			if got := stats.Breakdown.ByOS["unknown"]; got != tt.expectedOther {
+				t.Errorf("Expected %d unclassified downloads, got %d", tt.expectedOther, got)
+			}
+			if got := stats.Breakdown.ByArch["amd64"]; got != 65 {
+				t.Errorf("Expected 65 amd64 downloads, got %d", got)
+			}
+			if stats.Releases[0].Assets[0].Format != "tar.gz" {
+				t.Errorf("Expected first asset format tar.gz, got %q", stats.Releases[0].Assets[0].Format)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+// Star event types
+const (
+	eventMilestone = "milestone"
+	eventSpike     = "spike"
+	eventLoss      = "loss"
+)
+
+const (
+	// spikeBaselineDays is the rolling window the usual daily star rate is
+	// taken from
+	spikeBaselineDays = 28
+	// spikeMinBaselineDays is how many days of history a day needs before
+	// it can be a spike; a repository's first days have no usual rate
+	spikeMinBaselineDays = 7
+	// A day is a spike if it gains at least spikeMinStars and spikeFactor
+	// times the median of the baseline window
+	spikeMinStars = 20
+	spikeFactor   = 5
+)
+
+// starMilestones returns the milestone star counts up to stars: 100, 500,
+// 1k, 5k, 10k, 50k, and so on.
+func starMilestones(stars int) []int {
+	var milestones []int
+	for scale := 100; scale <= stars; scale *= 10 {
+		milestones = append(milestones, scale)
+		if scale*5 <= stars {
+			milestones = append(milestones, scale*5)
+		}
+	}
+	return milestones
+}
+
+// detectStarEvents finds milestone crossings and spikes in a cumulative
+// history, and star losses in the snapshots of the repository and its
+// current star count, which is negative when unknown. The current count
+// must be live: the history may be precomputed before the latest snapshot
+// or cut short, and would report the stars gained since as lost. Spikes
+// are not searched in sampled histories, whose interpolation would report
+// every gap between two samples as a jump.
+func detectStarEvents(history *cu.StarHistory, snapshotList []cu.Snapshot, current int) []cu.StarEvent {
+	events := make([]cu.StarEvent, 0)
+	if len(history.History) > 0 {
+		events = append(events, milestoneEvents(history.History)...)
+		if !history.Sampled {
+			events = append(events, spikeEvents(history.History)...)
+		}
+	}
+	events = append(events, lossEvents(snapshotList, current)...)
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Date.Before(events[j].Date)
+	})
+	return events
+}
+
+func milestoneEvents(history []cu.StarPoint) []cu.StarEvent {
+	var events []cu.StarEvent
+	i := 0
+	for _, milestone := range starMilestones(history[len(history)-1].Stars) {
+		for i < len(history) && history[i].Stars < milestone {
+			i++
+		}
+		if i == len(history) {
+			break
+		}
+		events = append(events, cu.StarEvent{
+			Type:        eventMilestone,
+			Date:        history[i].Date,
+			Stars:       milestone,
+			Description: fmt.Sprintf("Reached %d stars", milestone),
+		})
+	}
+	return events
+}
+
+// spikeEvents compares the stars of every day with the median of the days
+// before it. Consecutive spike days are reported as one event. The first
+// spikeMinBaselineDays days are never spikes, so a launch is not compared
+// with an empty baseline.
+func spikeEvents(history []cu.StarPoint) []cu.StarEvent {
+	daily := bucketStarHistory(history, intervalDaily, false)
+	gained := make([]float64, len(daily))
+	previous := 0
+	for i, point := range daily {
+		gained[i] = float64(point.Stars - previous)
+		previous = point.Stars
+	}
+
+	var events []cu.StarEvent
+	var spike *cu.StarEvent
+	for i, stars := range gained {
+		window := gained[max(0, i-spikeBaselineDays):i]
+		baseline := median(window)
+		if len(window) < spikeMinBaselineDays || stars < spikeMinStars || stars < spikeFactor*max(baseline, 1) {
+			spike = nil
+			continue
+		}
+
+		if spike == nil {
+			events = append(events, cu.StarEvent{
+				Type:     eventSpike,
+				Date:     daily[i].Date,
+				Baseline: baseline,
+			})
+			spike = &events[len(events)-1]
+		}
+		spike.Stars += int(stars)
+		spike.Days++
+	}
+
+	for i := range events {
+		events[i].Description = fmt.Sprintf("%d new stars in %d day(s), usually %.1f a day", events[i].Stars, events[i].Days, events[i].Baseline)
+	}
+	return events
+}
+
+// lossEvents reports drops in the star counts recorded by snapshots, and a
+// current count below the last snapshot. current is negative when unknown.
+func lossEvents(snapshotList []cu.Snapshot, current int) []cu.StarEvent {
+	var events []cu.StarEvent
+	for i := 1; i < len(snapshotList); i++ {
+		if lost := snapshotList[i-1].Stars - snapshotList[i].Stars; lost > 0 {
+			events = append(events, cu.StarEvent{
+				Type:        eventLoss,
+				Date:        snapshotList[i].Time,
+				Stars:       lost,
+				Description: fmt.Sprintf("Lost %d stars since %s", lost, snapshotList[i-1].Time.Format(time.DateOnly)),
+			})
+		}
+	}
+
+	if n := len(snapshotList); n > 0 && current >= 0 {
+		if lost := snapshotList[n-1].Stars - current; lost > 0 {
+			events = append(events, cu.StarEvent{
+				Type:        eventLoss,
+				Date:        time.Now().UTC(),
+				Stars:       lost,
+				Description: fmt.Sprintf("Lost %d stars since %s", lost, snapshotList[n-1].Time.Format(time.DateOnly)),
+			})
+		}
+	}
+	return events
+}
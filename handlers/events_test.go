@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+// dailyStarHistory builds a per-star history gaining perDay[i] stars on
+// day i.
+func dailyStarHistory(start time.Time, perDay []int) []cu.StarPoint {
+	var history []cu.StarPoint
+	for day, n := range perDay {
+		for i := 0; i < n; i++ {
+			history = append(history, cu.StarPoint{Date: start.AddDate(0, 0, day), Stars: len(history) + 1})
+		}
+	}
+	return history
+}
+
+func TestStarMilestones(t *testing.T) {
+	if got := starMilestones(12000); !reflect.DeepEqual(got, []int{100, 500, 1000, 5000, 10000}) {
+		t.Errorf("Unexpected milestones %v", got)
+	}
+	if got := starMilestones(99); len(got) != 0 {
+		t.Errorf("Expected no milestones below 100 stars, got %v", got)
+	}
+}
+
+func TestDetectStarEvents(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	perDay := make([]int, 40)
+	for i := range perDay {
+		perDay[i] = 3
+	}
+	// A launch on day 30 that lasts two days
+	perDay[30], perDay[31] = 400, 150
+
+	history := &cu.StarHistory{History: dailyStarHistory(start, perDay)}
+	snapshotList := []cu.Snapshot{
+		{Time: start.AddDate(0, 0, 10), Stars: 40},
+		{Time: start.AddDate(0, 0, 11), Stars: 35},
+		{Time: start.AddDate(0, 0, 39), Stars: 700},
+	}
+
+	// 90 stars before the launch, 550 during it and 24 after
+	events := detectStarEvents(history, snapshotList, 664)
+
+	var types []string
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	expected := []string{eventLoss, eventMilestone, eventSpike, eventMilestone, eventLoss}
+	if !reflect.DeepEqual(types, expected) {
+		t.Fatalf("Expected events %v, got %+v", expected, events)
+	}
+
+	if events[0].Stars != 5 {
+		t.Errorf("Expected 5 stars lost between snapshots, got %+v", events[0])
+	}
+	if events[1].Stars != 100 || !events[1].Date.Equal(start.AddDate(0, 0, 30)) {
+		t.Errorf("Unexpected milestone %+v", events[1])
+	}
+	spike := events[2]
+	if spike.Stars != 550 || spike.Days != 2 || spike.Baseline != 3 || !spike.Date.Equal(start.AddDate(0, 0, 30)) {
+		t.Errorf("Unexpected spike %+v", spike)
+	}
+	if events[3].Stars != 500 {
+		t.Errorf("Unexpected milestone %+v", events[3])
+	}
+	if events[4].Stars != 700-664 {
+		t.Errorf("Expected the current count to be 36 stars below the last snapshot, got %+v", events[4])
+	}
+}
+
+func TestSpikeEvents_Launch(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// A repository launching with a burst, then growing steadily
+	perDay := []int{60, 25, 10, 2, 2, 2, 2, 2, 2, 2, 2, 2, 40}
+
+	events := spikeEvents(dailyStarHistory(start, perDay))
+
+	if len(events) != 1 || !events[0].Date.Equal(start.AddDate(0, 0, 12)) || events[0].Stars != 40 {
+		t.Errorf("Expected only the spike on day 12, got %+v", events)
+	}
+}
+
+func TestDetectStarEvents_SampledHistorySkipsSpikes(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := &cu.StarHistory{
+		Sampled: true,
+		History: []cu.StarPoint{{Date: start, Stars: 1}, {Date: start.AddDate(0, 0, 30), Stars: 201}, {Date: start.AddDate(0, 0, 60), Stars: 401}},
+	}
+	for _, event := range detectStarEvents(history, nil, -1) {
+		if event.Type == eventSpike {
+			t.Errorf("Unexpected spike in a sampled history: %+v", event)
+		}
+	}
+}
+
+func TestHandleStarHistory_EventsUsePrecomputedHistory(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var stars atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/keploy/keploy":
+			fmt.Fprintf(w, `{"full_name":"keploy/keploy","stargazers_count":%d}`, stars.Load())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+	s := useStore(t)
+
+	c, err := NewCollector("@daily", "", []cu.TrackedTarget{{Repo: "https://github.com/keploy/keploy"}})
+	if err != nil {
+		t.Fatalf("NewCollector returned error: %v", err)
+	}
+	previous := collector
+	SetCollector(c)
+	t.Cleanup(func() { SetCollector(previous) })
+
+	// The collector computed the history at 100 stars; the repository had
+	// gained 5 more by the time it took the snapshot
+	history := cu.StarHistory{RepoName: "keploy/keploy", History: dailyStarHistory(start, []int{50, 50})}
+	if err := s.PutResult(resultStarHistory, "keploy/keploy", history); err != nil {
+		t.Fatalf("PutResult returned error: %v", err)
+	}
+	if err := s.Append(cu.Snapshot{RepoName: "keploy/keploy", Time: time.Now().UTC(), Stars: 105}); err != nil {
+		t.Fatalf("Append returned error: %v", err)
+	}
+
+	tests := []struct {
+		current  int32
+		expected int
+	}{
+		{105, 0},
+		{101, 4},
+	}
+	for _, tt := range tests {
+		stars.Store(tt.current)
+		rr := httptest.NewRecorder()
+		HandleStarHistory(rr, httptest.NewRequest(http.MethodGet, "/star-history?repo=https://github.com/keploy/keploy&events=1", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+
+		var result cu.MultiRepoStarHistory
+		if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+			t.Fatalf("Error decoding response: %v", err)
+		}
+		lost := 0
+		for _, event := range result.Repositories[0].Events {
+			if event.Type == eventLoss {
+				lost += event.Stars
+			}
+		}
+		if lost != tt.expected {
+			t.Errorf("With %d stars now, expected %d stars lost, got %d in %+v", tt.current, tt.expected, lost, result.Repositories[0].Events)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/forecast"
+)
+
+const (
+	defaultForecastWindow  = 90
+	defaultForecastHorizon = 180
+	maxForecastHorizon     = 5 * 365
+
+	// maxForecastPoints bounds the projection; longer horizons are sampled
+	// every few days
+	maxForecastPoints = 100
+	// milestoneSearchDays is how far ahead milestone dates are searched
+	milestoneSearchDays = 10 * 365
+)
+
+// forecastOptions configure the optional forecast of /star-history
+type forecastOptions struct {
+	// Model is linear, exponential or logistic; empty disables forecasting
+	Model string
+	// Window is the number of trailing days the trend is fitted to and
+	// Horizon the number of days projected
+	Window, Horizon int
+	// Milestones are star counts to date; by default the next two round
+	// numbers above the current count
+	Milestones []int
+}
+
+func parseForecastOptions(query url.Values) (forecastOptions, error) {
+	opts := forecastOptions{Window: defaultForecastWindow, Horizon: defaultForecastHorizon}
+
+	switch model := query.Get("forecast"); model {
+	case "":
+		return opts, nil
+	case forecast.Linear, forecast.Exponential, forecast.Logistic:
+		opts.Model = model
+	default:
+		return opts, fmt.Errorf("unknown forecast %q, expected linear, exponential or logistic", model)
+	}
+
+	if window := query.Get("window"); window != "" {
+		n, err := strconv.Atoi(window)
+		if err != nil || n < 3 {
+			return opts, fmt.Errorf("window must be a number of days of at least 3")
+		}
+		opts.Window = n
+	}
+	if horizon := query.Get("horizon"); horizon != "" {
+		n, err := strconv.Atoi(horizon)
+		if err != nil || n < 1 || n > maxForecastHorizon {
+			return opts, fmt.Errorf("horizon must be a number of days between 1 and %d", maxForecastHorizon)
+		}
+		opts.Horizon = n
+	}
+	if milestones := query.Get("milestones"); milestones != "" {
+		for _, value := range strings.Split(milestones, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 1 {
+				return opts, fmt.Errorf("invalid milestone %q", value)
+			}
+			opts.Milestones = append(opts.Milestones, n)
+		}
+	}
+	return opts, nil
+}
+
+// nextMilestones returns the next n round star counts (1, 2.5 and 5 times
+// a power of ten) above stars.
+func nextMilestones(stars, n int) []int {
+	milestones := make([]int, 0, n)
+	for scale := 100; len(milestones) < n; scale *= 10 {
+		for _, m := range []int{scale, scale * 5 / 2, scale * 5} {
+			if m > stars && len(milestones) < n {
+				milestones = append(milestones, m)
+			}
+		}
+	}
+	return milestones
+}
+
+// forecastStars fits the requested trend to the days up to now and projects
+// it from now. Days between the points of sampled histories are
+// interpolated. Days since the last star are part of the trend, so a
+// repository that stopped gaining stars is projected flat rather than from
+// its last growth. Histories too short to fit yield a forecast carrying
+// only an error, so the history itself is still served.
+func forecastStars(history []cu.StarPoint, sampled bool, opts forecastOptions, now time.Time) *cu.StarForecast {
+	result := &cu.StarForecast{
+		Model:      opts.Model,
+		WindowDays: opts.Window,
+		Projection: make([]cu.ForecastPoint, 0),
+		Milestones: make([]cu.StarMilestone, 0),
+	}
+	if len(history) == 0 {
+		result.Error = forecast.ErrTooFewPoints.Error()
+		return result
+	}
+
+	end := now
+	if last := history[len(history)-1].Date; last.After(end) {
+		end = last
+	}
+	daily := bucketSamples(history, history[0].Date, end, intervalDaily, sampled)
+	if len(daily) > opts.Window {
+		daily = daily[len(daily)-opts.Window:]
+	}
+	origin := daily[0].Date
+	xs, ys := make([]float64, len(daily)), make([]float64, len(daily))
+	for i, point := range daily {
+		xs[i] = point.Date.Sub(origin).Hours() / 24
+		ys[i] = float64(point.Stars)
+	}
+
+	model, err := forecast.Fit(opts.Model, xs, ys)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.RSquared = model.RSquared()
+	result.Capacity = model.Capacity()
+
+	last := daily[len(daily)-1].Date
+	lastX := xs[len(xs)-1]
+	step := max(1, int(math.Ceil(float64(opts.Horizon)/maxForecastPoints)))
+	for day := step; day <= opts.Horizon; day += step {
+		y, lower, upper := model.Predict(lastX + float64(day))
+		result.Projection = append(result.Projection, cu.ForecastPoint{
+			Date:  last.AddDate(0, 0, day),
+			Stars: int(math.Round(y)),
+			Lower: int(math.Round(lower)),
+			Upper: int(math.Round(upper)),
+		})
+	}
+
+	current := history[len(history)-1].Stars
+	milestones := opts.Milestones
+	if len(milestones) == 0 {
+		milestones = nextMilestones(current, 2)
+	}
+	for _, stars := range milestones {
+		result.Milestones = append(result.Milestones, starMilestone(history, model, last, lastX, stars))
+	}
+	return result
+}
+
+// starMilestone dates a milestone from the history if it has been reached,
+// and from the projection otherwise.
+func starMilestone(history []cu.StarPoint, model *forecast.Model, last time.Time, lastX float64, stars int) cu.StarMilestone {
+	milestone := cu.StarMilestone{Stars: stars}
+	for _, point := range history {
+		if point.Stars >= stars {
+			date := point.Date
+			milestone.Reached = true
+			milestone.Date = &date
+			return milestone
+		}
+	}
+
+	target := float64(stars)
+	for day := 1; day <= milestoneSearchDays && milestone.Latest == nil; day++ {
+		y, lower, upper := model.Predict(lastX + float64(day))
+		date := last.AddDate(0, 0, day)
+		if milestone.Earliest == nil && upper >= target {
+			milestone.Earliest = &date
+		}
+		if milestone.Date == nil && y >= target {
+			milestone.Date = &date
+		}
+		if lower >= target {
+			milestone.Latest = &date
+		}
+	}
+	return milestone
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+func TestParseForecastOptions(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected forecastOptions
+		hasError bool
+	}{
+		{"", forecastOptions{Window: defaultForecastWindow, Horizon: defaultForecastHorizon}, false},
+		{"forecast=linear&window=30&horizon=365&milestones=10000,20000", forecastOptions{Model: "linear", Window: 30, Horizon: 365, Milestones: []int{10000, 20000}}, false},
+		{"forecast=quadratic", forecastOptions{}, true},
+		{"forecast=linear&window=2", forecastOptions{}, true},
+		{"forecast=logistic&horizon=5000", forecastOptions{}, true},
+		{"forecast=exponential&milestones=10k", forecastOptions{}, true},
+	}
+
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		opts, err := parseForecastOptions(query)
+		if (err != nil) != tt.hasError {
+			t.Errorf("parseForecastOptions(%q) error = %v, hasError %v", tt.query, err, tt.hasError)
+			continue
+		}
+		if !tt.hasError && !reflect.DeepEqual(opts, tt.expected) {
+			t.Errorf("parseForecastOptions(%q) = %+v, expected %+v", tt.query, opts, tt.expected)
+		}
+	}
+}
+
+func TestNextMilestones(t *testing.T) {
+	tests := []struct {
+		stars    int
+		expected []int
+	}{
+		{0, []int{100, 250}},
+		{240, []int{250, 500}},
+		{7300, []int{10000, 25000}},
+		{10000, []int{25000, 50000}},
+	}
+	for _, tt := range tests {
+		if got := nextMilestones(tt.stars, 2); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("nextMilestones(%d) = %v, expected %v", tt.stars, got, tt.expected)
+		}
+	}
+}
+
+func TestForecastStars(t *testing.T) {
+	// Ten stars a day for 60 days
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var history []cu.StarPoint
+	for day := 0; day < 60; day++ {
+		for i := 0; i < 10; i++ {
+			history = append(history, cu.StarPoint{Date: start.AddDate(0, 0, day), Stars: len(history) + 1})
+		}
+	}
+
+	now := history[len(history)-1].Date
+	result := forecastStars(history, false, forecastOptions{Model: "linear", Window: 30, Horizon: 40, Milestones: []int{500, 1000}}, now)
+
+	if result.Error != "" {
+		t.Fatalf("Unexpected error %s", result.Error)
+	}
+	if len(result.Projection) != 40 {
+		t.Fatalf("Expected 40 projected days, got %d", len(result.Projection))
+	}
+	if got := result.Projection[39]; got.Stars != 1000 || got.Lower > got.Stars || got.Upper < got.Stars {
+		t.Errorf("Expected about 1000 stars 40 days out, got %+v", got)
+	}
+
+	reached, ahead := result.Milestones[0], result.Milestones[1]
+	if !reached.Reached || !reached.Date.Equal(start.AddDate(0, 0, 49)) {
+		t.Errorf("Expected 500 stars reached on day 49, got %+v", reached)
+	}
+	if ahead.Reached || ahead.Date == nil || !ahead.Date.Equal(time.Date(2024, 4, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 1000 stars projected for 2024-04-09, got %+v", ahead)
+	}
+	if ahead.Earliest == nil || ahead.Latest == nil || ahead.Earliest.After(*ahead.Date) || ahead.Latest.Before(*ahead.Date) {
+		t.Errorf("Expected the milestone date within its bounds, got %+v", ahead)
+	}
+}
+
+func TestForecastStars_TooShort(t *testing.T) {
+	history := []cu.StarPoint{{Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Stars: 1}}
+	result := forecastStars(history, false, forecastOptions{Model: "linear", Window: 30, Horizon: 10}, history[0].Date)
+	if result.Error == "" || len(result.Projection) != 0 {
+		t.Errorf("Expected an error and no projection, got %+v", result)
+	}
+}
+
+func TestForecastStars_StaleHistory(t *testing.T) {
+	// Ten stars a day for 60 days, then none for 200 days
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var history []cu.StarPoint
+	for day := 0; day < 60; day++ {
+		for i := 0; i < 10; i++ {
+			history = append(history, cu.StarPoint{Date: start.AddDate(0, 0, day), Stars: len(history) + 1})
+		}
+	}
+	now := start.AddDate(0, 0, 260)
+
+	result := forecastStars(history, false, forecastOptions{Model: "linear", Window: 30, Horizon: 10, Milestones: []int{1000}}, now)
+
+	if result.Error != "" {
+		t.Fatalf("Unexpected error %s", result.Error)
+	}
+	if first := result.Projection[0]; !first.Date.After(now) || first.Stars != 600 {
+		t.Errorf("Expected a flat projection of 600 stars starting after now, got %+v", first)
+	}
+	if milestone := result.Milestones[0]; milestone.Reached || milestone.Date != nil {
+		t.Errorf("Expected 1000 stars not to be projected for a stale repository, got %+v", milestone)
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/store"
+)
+
+// snapshots is where historical metrics are kept; nil disables the history
+// endpoints.
+var snapshots *store.Store
+
+// SetStore sets the snapshot store used by the history endpoints.
+func SetStore(s *store.Store) {
+	snapshots = s
+}
+
+// takeSnapshot fetches the current downloads, stars and contributor count of
+// a repository and records them in the snapshot store.
+func takeSnapshot(ctx context.Context, ref repoRef) (*cu.Snapshot, error) {
+	client := clientFor(ref.Config)
+
+	repository, err := client.GetRepository(ctx, ref.Owner, ref.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	releases, err := getAllReleases(ctx, ref.Owner, ref.Repo, ref.Config)
+	if err != nil {
+		return nil, err
+	}
+	downloads := calculateDownloadStats(releases)
+	downloads.RepoName = ref.Name()
+
+	contributors, err := client.ListContributors(ctx, ref.Owner, ref.Repo, false)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := cu.Snapshot{
+		RepoName:     ref.Key(),
+		Time:         time.Now().UTC(),
+		Stars:        repository.StargazersCount,
+		Contributors: len(contributors),
+		Downloads:    downloads,
+	}
+	if err := snapshots.Append(snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// historyPoints reduces stored snapshots to chartable points
+func historyPoints(snapshotList []cu.Snapshot) []cu.MetricPoint {
+	points := make([]cu.MetricPoint, 0, len(snapshotList))
+	for _, snapshot := range snapshotList {
+		point := cu.MetricPoint{
+			Time:         snapshot.Time,
+			Stars:        snapshot.Stars,
+			Contributors: snapshot.Contributors,
+		}
+		if snapshot.Downloads != nil {
+			point.TotalDownloads = snapshot.Downloads.TotalDownloads
+			point.ReleaseDownloads = make(map[string]int, len(snapshot.Downloads.Releases))
+			for _, release := range snapshot.Downloads.Releases {
+				point.ReleaseDownloads[release.TagName] = release.TotalDownloads
+			}
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+// parseTimeParam accepts RFC 3339 timestamps and plain dates; an empty value
+// yields the zero time
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected YYYY-MM-DD or RFC 3339", value)
+	}
+	return t, nil
+}
+
+// parseEndParam parses the end of a range like parseTimeParam, except that
+// a plain date includes the whole day
+func parseEndParam(value string) (time.Time, error) {
+	t, err := parseTimeParam(value)
+	if err != nil || value == "" {
+		return t, err
+	}
+	if _, err := time.Parse(time.DateOnly, value); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return t, nil
+}
+
+func HandleRepoHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if snapshots == nil {
+		http.Error(w, "Snapshot storage is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	repoURL := r.URL.Query().Get("repo")
+	if repoURL == "" {
+		http.Error(w, "Repository URL is required", http.StatusBadRequest)
+		return
+	}
+
+	host, owner, repo, err := parseRepoURL(repoURL)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid repository URL: %v", err), http.StatusBadRequest)
+		return
+	}
+	ref := repoRef{Owner: owner, Repo: repo, Config: forHost(nil, host)}
+
+	from, err := parseTimeParam(r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := parseEndParam(r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snapshotList, err := snapshots.Snapshots(ref.Key(), from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cu.RepoHistory{
+		RepoName: ref.Key(),
+		Points:   historyPoints(snapshotList),
+	})
+}
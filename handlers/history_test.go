@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/store"
+)
+
+// useStore installs a fresh snapshot store for the duration of the test.
+func useStore(t *testing.T) *store.Store {
+	t.Helper()
+	s, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Error opening store: %v", err)
+	}
+	previous := snapshots
+	SetStore(s)
+	t.Cleanup(func() { SetStore(previous) })
+	return s
+}
+
+func TestHandleRepoHistory_NotConfigured(t *testing.T) {
+	previous := snapshots
+	SetStore(nil)
+	t.Cleanup(func() { SetStore(previous) })
+
+	rr := httptest.NewRecorder()
+	HandleRepoHistory(rr, httptest.NewRequest(http.MethodGet, "/repo-history?repo=https://github.com/keploy/keploy", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %v, got %v", http.StatusServiceUnavailable, rr.Code)
+	}
+}
+
+func TestHandleRepoHistory(t *testing.T) {
+	s := useStore(t)
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i, downloads := range []int{10, 25, 60} {
+		s.Append(cu.Snapshot{
+			RepoName: "keploy/keploy",
+			Time:     base.AddDate(0, 0, i),
+			Stars:    100 + i,
+			Downloads: &cu.DownloadStats{
+				TotalDownloads: downloads,
+				Releases:       []cu.ReleaseDownloadStats{{TagName: "v1.0.0", TotalDownloads: downloads}},
+			},
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	HandleRepoHistory(rr, httptest.NewRequest(http.MethodGet, "/repo-history?repo=https://github.com/keploy/keploy&from=2024-03-02", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var history cu.RepoHistory
+	if err := json.Unmarshal(rr.Body.Bytes(), &history); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if len(history.Points) != 2 {
+		t.Fatalf("Expected 2 points, got %d", len(history.Points))
+	}
+	if history.Points[1].TotalDownloads != 60 || history.Points[1].ReleaseDownloads["v1.0.0"] != 60 {
+		t.Errorf("Unexpected last point %+v", history.Points[1])
+	}
+
+	// A plain to date includes snapshots taken later that day
+	s.Append(cu.Snapshot{RepoName: "keploy/keploy", Time: base.AddDate(0, 0, 1).Add(12 * time.Hour), Stars: 102})
+	rr = httptest.NewRecorder()
+	HandleRepoHistory(rr, httptest.NewRequest(http.MethodGet, "/repo-history?repo=https://github.com/keploy/keploy&from=2024-03-02&to=2024-03-02", nil))
+	history = cu.RepoHistory{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &history); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if len(history.Points) != 2 || history.Points[1].Stars != 102 {
+		t.Errorf("Expected both snapshots of 2024-03-02, got %+v", history.Points)
+	}
+}
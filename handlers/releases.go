@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"sort"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/semver"
+)
+
+// Version line granularities, selected with the group query parameter of
+// /repo-stats
+const (
+	groupMajor = "major"
+	groupMinor = "minor"
+
+	unversionedLine = "unversioned"
+)
+
+// Release orders, selected with the sort query parameter of /repo-stats
+const (
+	sortDate    = "date"
+	sortVersion = "version"
+)
+
+// compareReleases orders releases by semantic version, falling back to the
+// creation date for equal versions and for tags that are not versions.
+// Versioned releases rank above unversioned ones.
+func compareReleases(tagA string, createdA time.Time, tagB string, createdB time.Time) int {
+	a, okA := semver.Parse(tagA)
+	b, okB := semver.Parse(tagB)
+	switch {
+	case okA && !okB:
+		return 1
+	case !okA && okB:
+		return -1
+	case okA && okB:
+		if c := semver.Compare(a, b); c != 0 {
+			return c
+		}
+	}
+	return createdA.Compare(createdB)
+}
+
+// releasesByDate returns a copy of releases ordered by creation date, oldest
+// first. DownloadStats list releases newest first, or by version with
+// sort=version; timelines need this order whichever was requested.
+func releasesByDate(releases []cu.ReleaseDownloadStats) []cu.ReleaseDownloadStats {
+	sorted := slices.Clone(releases)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+	return sorted
+}
+
+// releaseFilter selects the releases /repo-stats reports on
+type releaseFilter struct {
+	ExcludePrereleases bool
+	ExcludeDrafts      bool
+	// TagPattern is a glob such as v1.* matched against the tag name
+	TagPattern string
+	From, To   time.Time
+	// Group rolls releases up per major or minor version line
+	Group string
+	// Sort is the order of the releases, by creation date unless it is
+	// sortVersion
+	Sort string
+}
+
+func parseReleaseFilter(query url.Values) (releaseFilter, error) {
+	filter := releaseFilter{
+		ExcludePrereleases: query.Get("exclude_prereleases") == "1",
+		ExcludeDrafts:      query.Get("exclude_drafts") == "1",
+		TagPattern:         query.Get("tag"),
+	}
+
+	if filter.TagPattern != "" {
+		if _, err := path.Match(filter.TagPattern, ""); err != nil {
+			return filter, fmt.Errorf("invalid tag pattern %q", filter.TagPattern)
+		}
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(query.Get("from")); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseEndParam(query.Get("to")); err != nil {
+		return filter, err
+	}
+
+	switch group := query.Get("group"); group {
+	case "", groupMajor, groupMinor:
+		filter.Group = group
+	default:
+		return filter, fmt.Errorf("unknown group %q, expected major or minor", group)
+	}
+
+	switch order := query.Get("sort"); order {
+	case "", sortDate, sortVersion:
+		filter.Sort = order
+	default:
+		return filter, fmt.Errorf("unknown sort %q, expected date or version", order)
+	}
+	return filter, nil
+}
+
+// matches reports whether the filter keeps release. Tags with a semver
+// prerelease suffix count as prereleases even if GitHub does not flag them.
+func (f releaseFilter) matches(release cu.ReleaseDownloadStats) bool {
+	if f.ExcludeDrafts && release.Draft {
+		return false
+	}
+	if f.ExcludePrereleases {
+		if release.Prerelease {
+			return false
+		}
+		if v, ok := semver.Parse(release.TagName); ok && v.IsPrerelease() {
+			return false
+		}
+	}
+	if f.TagPattern != "" {
+		if ok, _ := path.Match(f.TagPattern, release.TagName); !ok {
+			return false
+		}
+	}
+	if !f.From.IsZero() && release.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && release.CreatedAt.After(f.To) {
+		return false
+	}
+	return true
+}
+
+// filterReleases drops the releases the filter rejects, recomputes the total
+// and orders the rest by version if the filter asks for it. Tags that are
+// not versions, such as nightly builds, sort last.
+func filterReleases(stats *cu.DownloadStats, filter releaseFilter) {
+	kept := make([]cu.ReleaseDownloadStats, 0, len(stats.Releases))
+	stats.TotalDownloads = 0
+	for _, release := range stats.Releases {
+		if !filter.matches(release) {
+			continue
+		}
+		kept = append(kept, release)
+		stats.TotalDownloads += release.TotalDownloads
+	}
+	if filter.Sort == sortVersion {
+		sort.SliceStable(kept, func(i, j int) bool {
+			return compareReleases(kept[i].TagName, kept[i].CreatedAt, kept[j].TagName, kept[j].CreatedAt) > 0
+		})
+	}
+	stats.Releases = kept
+}
+
+// versionLines rolls the releases of stats up per major or minor version
+// line, newest line first.
+func versionLines(stats *cu.DownloadStats, group string) []cu.VersionLine {
+	lines := make(map[string]*cu.VersionLine)
+	versions := make(map[string]semver.Version)
+	latest := make(map[string]cu.ReleaseDownloadStats)
+
+	for _, release := range stats.Releases {
+		name := unversionedLine
+		v, ok := semver.Parse(release.TagName)
+		if ok {
+			name = v.MajorLine()
+			if group == groupMinor {
+				name = v.MinorLine()
+			}
+		}
+
+		line, exists := lines[name]
+		if !exists {
+			line = &cu.VersionLine{Line: name, FirstReleasedAt: release.CreatedAt}
+			lines[name] = line
+			versions[name] = v
+		}
+		line.Releases++
+		line.TotalDownloads += release.TotalDownloads
+		if release.CreatedAt.Before(line.FirstReleasedAt) {
+			line.FirstReleasedAt = release.CreatedAt
+		}
+		if release.CreatedAt.After(line.LatestReleasedAt) {
+			line.LatestReleasedAt = release.CreatedAt
+		}
+		if current, ok := latest[name]; !ok || compareReleases(release.TagName, release.CreatedAt, current.TagName, current.CreatedAt) > 0 {
+			latest[name] = release
+			line.Latest = release.TagName
+		}
+	}
+
+	result := make([]cu.VersionLine, 0, len(lines))
+	for _, line := range lines {
+		result = append(result, *line)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Line == unversionedLine || b.Line == unversionedLine {
+			return b.Line == unversionedLine && a.Line != unversionedLine
+		}
+		return semver.Compare(versions[a.Line], versions[b.Line]) > 0
+	})
+	return result
+}
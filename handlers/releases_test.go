@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+func TestCalculateDownloadStats_Sort(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	releases := []cu.Release{
+		{TagName: "v1.10.0", CreatedAt: day(1)},
+		{TagName: "nightly-2024-03-09", CreatedAt: day(9)},
+		{TagName: "v2.0.0-rc.1", CreatedAt: day(2)},
+		// A backported patch published after the newer line
+		{TagName: "v1.9.3", CreatedAt: day(5)},
+		{TagName: "v2.0.0", CreatedAt: day(3)},
+	}
+
+	tests := []struct {
+		sort     string
+		expected []string
+	}{
+		{"", []string{"nightly-2024-03-09", "v1.9.3", "v2.0.0", "v2.0.0-rc.1", "v1.10.0"}},
+		{sortDate, []string{"nightly-2024-03-09", "v1.9.3", "v2.0.0", "v2.0.0-rc.1", "v1.10.0"}},
+		{sortVersion, []string{"v2.0.0", "v2.0.0-rc.1", "v1.10.0", "v1.9.3", "nightly-2024-03-09"}},
+	}
+
+	for _, tt := range tests {
+		stats := calculateDownloadStats(slices.Clone(releases))
+		filterReleases(stats, releaseFilter{Sort: tt.sort})
+
+		var tags []string
+		for _, release := range stats.Releases {
+			tags = append(tags, release.TagName)
+		}
+		if !slices.Equal(tags, tt.expected) {
+			t.Errorf("sort=%s: expected releases %v, got %v", tt.sort, tt.expected, tags)
+		}
+	}
+}
+
+func TestParseReleaseFilter(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr bool
+	}{
+		{"", false},
+		{"group=minor&exclude_prereleases=1&tag=v1.*&from=2024-01-01", false},
+		{"group=patch", true},
+		{"tag=[", true},
+		{"to=yesterday", true},
+		{"sort=version", false},
+		{"sort=downloads", true},
+	}
+
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		if _, err := parseReleaseFilter(query); (err != nil) != tt.wantErr {
+			t.Errorf("parseReleaseFilter(%q) returned error %v, expected error: %v", tt.query, err, tt.wantErr)
+		}
+	}
+}
+
+func TestReleaseFilterTo(t *testing.T) {
+	tests := []struct {
+		to        string
+		createdAt time.Time
+		expected  bool
+	}{
+		{"2024-06-30", time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC), true},
+		{"2024-06-30", time.Date(2024, 6, 30, 23, 59, 59, 0, time.UTC), true},
+		{"2024-06-30", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), false},
+		{"2024-06-30T12:00:00Z", time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC), true},
+		{"2024-06-30T12:00:00Z", time.Date(2024, 6, 30, 12, 0, 1, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		filter, err := parseReleaseFilter(url.Values{"to": {tt.to}})
+		if err != nil {
+			t.Fatalf("parseReleaseFilter(to=%s) returned error: %v", tt.to, err)
+		}
+		if got := filter.matches(cu.ReleaseDownloadStats{TagName: "v1.0.0", CreatedAt: tt.createdAt}); got != tt.expected {
+			t.Errorf("to=%s: expected release created at %v to match %v, got %v", tt.to, tt.createdAt, tt.expected, got)
+		}
+	}
+}
+
+func TestHandleRepoStats_VersionLines(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"tag_name":"v2.1.0","created_at":"2024-04-01T00:00:00Z","assets":[{"name":"a","download_count":40}]},
+			{"tag_name":"v2.1.0-beta.1","created_at":"2024-03-20T00:00:00Z","prerelease":true,"assets":[{"name":"a","download_count":3}]},
+			{"tag_name":"v2.0.0","created_at":"2024-03-01T00:00:00Z","assets":[{"name":"a","download_count":20}]},
+			{"tag_name":"v1.4.2","created_at":"2024-03-10T00:00:00Z","assets":[{"name":"a","download_count":7}]},
+			{"tag_name":"v1.4.0","created_at":"2024-01-01T00:00:00Z","assets":[{"name":"a","download_count":100}]},
+			{"tag_name":"draft","created_at":"2024-04-02T00:00:00Z","draft":true,"assets":[{"name":"a","download_count":0}]}
+		]`))
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedTotal int
+		expectedLines []cu.VersionLine
+	}{
+		{
+			name:          "major lines without prereleases and drafts",
+			query:         "group=major&exclude_prereleases=1&exclude_drafts=1",
+			expectedTotal: 167,
+			expectedLines: []cu.VersionLine{
+				{Line: "v2", Releases: 2, TotalDownloads: 60, Latest: "v2.1.0"},
+				{Line: "v1", Releases: 2, TotalDownloads: 107, Latest: "v1.4.2"},
+			},
+		},
+		{
+			name:          "minor lines of v2 since March",
+			query:         "group=minor&tag=v2.*&from=2024-03-01",
+			expectedTotal: 63,
+			expectedLines: []cu.VersionLine{
+				{Line: "v2.1", Releases: 2, TotalDownloads: 43, Latest: "v2.1.0"},
+				{Line: "v2.0", Releases: 1, TotalDownloads: 20, Latest: "v2.0.0"},
+			},
+		},
+		{
+			name:          "unversioned tags last",
+			query:         "group=major&to=2024-04-30",
+			expectedTotal: 170,
+			expectedLines: []cu.VersionLine{
+				{Line: "v2", Releases: 3, TotalDownloads: 63, Latest: "v2.1.0"},
+				{Line: "v1", Releases: 2, TotalDownloads: 107, Latest: "v1.4.2"},
+				{Line: "unversioned", Releases: 1, TotalDownloads: 0, Latest: "draft"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			HandleRepoStats(rr, httptest.NewRequest(http.MethodGet, "/repo-stats?repo=https://github.com/keploy/keploy&"+tt.query, nil))
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+			}
+
+			var stats cu.DownloadStats
+			if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+				t.Fatalf("Error decoding response: %v", err)
+			}
+			if stats.TotalDownloads != tt.expectedTotal {
+				t.Errorf("Expected %d downloads, got %d", tt.expectedTotal, stats.TotalDownloads)
+			}
+			if len(stats.Lines) != len(tt.expectedLines) {
+				t.Fatalf("Expected %d lines, got %+v", len(tt.expectedLines), stats.Lines)
+			}
+			for i, want := range tt.expectedLines {
+				got := stats.Lines[i]
+				if got.Line != want.Line || got.Releases != want.Releases || got.TotalDownloads != want.TotalDownloads || got.Latest != want.Latest {
+					t.Errorf("Expected line %+v, got %+v", want, got)
+				}
+			}
+		})
+	}
+}
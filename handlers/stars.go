@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/github"
+	"github.com/keploy/gitstats/pool"
+)
+
+// Star history modes, selected with the mode query parameter of
+// /star-history
+const (
+	// starModeREST walks every page of the REST stargazers endpoint. It is
+	// exact but slow, and stops at GitHub's 400 page limit.
+	starModeREST = "rest"
+	// starModeGraphQL walks the stargazers connection of the GraphQL API,
+	// which has no page limit but requires a token.
+	starModeGraphQL = "graphql"
+	// starModeSample fetches a fixed number of evenly spaced REST pages and
+	// interpolates the curve between them, like star-history.com does.
+	starModeSample = "sample"
+
+	defaultStarSamples = 15
+	maxStarSamples     = 100
+	stargazersPerPage  = 100
+
+	// Star series, selected with the series query parameter
+	seriesCumulative = "cumulative"
+	seriesNew        = "new"
+
+	maxStarPoints = 1000
+)
+
+type starHistoryOptions struct {
+	Mode    string
+	Samples int
+	// Interval buckets the history by day, week or month; Points resamples
+	// it to a fixed number of evenly spaced points. At most one is set.
+	Interval string
+	Points   int
+	// Series is cumulative (total stars) or new (stars gained per period)
+	Series string
+	// Forecast projects the trend of the history
+	Forecast forecastOptions
+	// Events annotates milestones, spikes and star losses
+	Events bool
+	// Align adds every history rebased to days since a common origin
+	Align alignOptions
+}
+
+func parseStarHistoryOptions(query url.Values) (starHistoryOptions, error) {
+	opts := starHistoryOptions{Mode: starModeREST, Samples: defaultStarSamples}
+
+	switch mode := query.Get("mode"); mode {
+	case "", starModeREST:
+	case starModeGraphQL, starModeSample:
+		opts.Mode = mode
+	default:
+		return opts, fmt.Errorf("unknown mode %q, expected rest, graphql or sample", mode)
+	}
+
+	if samples := query.Get("samples"); samples != "" {
+		n, err := strconv.Atoi(samples)
+		if err != nil || n < 2 || n > maxStarSamples {
+			return opts, fmt.Errorf("samples must be a number between 2 and %d", maxStarSamples)
+		}
+		opts.Samples = n
+	}
+
+	switch interval := query.Get("interval"); interval {
+	case "", intervalDaily, intervalWeekly, intervalMonthly:
+		opts.Interval = interval
+	default:
+		return opts, fmt.Errorf("unknown interval %q, expected daily, weekly or monthly", interval)
+	}
+
+	if points := query.Get("points"); points != "" {
+		n, err := strconv.Atoi(points)
+		if err != nil || n < 2 || n > maxStarPoints {
+			return opts, fmt.Errorf("points must be a number between 2 and %d", maxStarPoints)
+		}
+		if opts.Interval != "" {
+			return opts, fmt.Errorf("interval and points cannot be combined")
+		}
+		opts.Points = n
+	}
+
+	switch series := query.Get("series"); series {
+	case "", seriesCumulative:
+		opts.Series = seriesCumulative
+	case seriesNew:
+		if opts.Interval == "" && opts.Points == 0 {
+			return opts, fmt.Errorf("series=new requires an interval or a number of points")
+		}
+		opts.Series = seriesNew
+	default:
+		return opts, fmt.Errorf("unknown series %q, expected cumulative or new", series)
+	}
+
+	opts.Events = query.Get("events") == "1"
+
+	var err error
+	if opts.Align, err = parseAlignOptions(query); err != nil {
+		return opts, err
+	}
+	opts.Forecast, err = parseForecastOptions(query)
+	return opts, err
+}
+
+// aggregateStarHistory reduces a per-star history to one point per interval
+// or to a fixed number of points, as requested. Each point holds the total
+// stars at its time, or with the new series the stars gained since the
+// previous point.
+func aggregateStarHistory(history *cu.StarHistory, opts starHistoryOptions) {
+	if len(history.History) == 0 {
+		return
+	}
+
+	var points []cu.StarPoint
+	switch {
+	case opts.Interval != "":
+		points = bucketStarHistory(history.History, opts.Interval, history.Sampled)
+	case opts.Points > 0:
+		points = resampleStarHistory(history.History, opts.Points, history.Sampled)
+	default:
+		return
+	}
+
+	if opts.Series == seriesNew {
+		differencePoints(points)
+	}
+
+	history.History = points
+	history.Interval = opts.Interval
+	history.Series = opts.Series
+}
+
+// differencePoints turns cumulative points into the increase since the
+// previous point, in place
+func differencePoints(points []cu.StarPoint) {
+	previous := 0
+	for i := range points {
+		total := points[i].Stars
+		points[i].Stars = total - previous
+		previous = total
+	}
+}
+
+// bucketStarHistory returns the star count at the end of every interval
+// from the first star up to the last, including intervals without stars.
+// Counts between the points of sampled histories are interpolated.
+func bucketStarHistory(history []cu.StarPoint, interval string, sampled bool) []cu.StarPoint {
+	return bucketSamples(history, history[0].Date, history[len(history)-1].Date, interval, sampled)
+}
+
+// bucketPoints returns the count at the end of every interval from the one
+// holding from up to the one holding to. Cumulative history before from
+// is carried into the first interval.
+func bucketPoints(history []cu.StarPoint, from, to time.Time, interval string) []cu.StarPoint {
+	return bucketSamples(history, from, to, interval, false)
+}
+
+// bucketSamples is bucketPoints for histories that may be sampled. The
+// count of sampled histories at the end of an interval is interpolated
+// linearly between the samples around it rather than held at the last one.
+func bucketSamples(history []cu.StarPoint, from, to time.Time, interval string, sampled bool) []cu.StarPoint {
+	last := bucketStart(to, interval)
+	points := make([]cu.StarPoint, 0)
+
+	i, stars := 0, 0
+	for start := bucketStart(from, interval); !start.After(last); start = nextBucket(start, interval) {
+		end := nextBucket(start, interval)
+		for i < len(history) && history[i].Date.Before(end) {
+			stars = history[i].Stars
+			i++
+		}
+		if sampled && i > 0 && i < len(history) {
+			stars = interpolateStars(history[i-1], history[i], end)
+		}
+		points = append(points, cu.StarPoint{Date: start, Stars: stars})
+	}
+	return points
+}
+
+// interpolateStars returns the count at t on the line between two points.
+func interpolateStars(a, b cu.StarPoint, t time.Time) int {
+	span := b.Date.Sub(a.Date)
+	if span <= 0 {
+		return a.Stars
+	}
+	return a.Stars + int(math.Round(float64(b.Stars-a.Stars)*float64(t.Sub(a.Date))/float64(span)))
+}
+
+// resampleStarHistory returns the star count at n evenly spaced times from
+// the first to the last point of history, interpolated between the points
+// of sampled histories.
+func resampleStarHistory(history []cu.StarPoint, n int, sampled bool) []cu.StarPoint {
+	first, last := history[0].Date, history[len(history)-1].Date
+	step := last.Sub(first) / time.Duration(n-1)
+	points := make([]cu.StarPoint, 0, n)
+
+	i, stars := 0, 0
+	for k := 0; k < n; k++ {
+		at := first.Add(time.Duration(k) * step)
+		if k == n-1 {
+			at = last
+		}
+		for i < len(history) && !history[i].Date.After(at) {
+			stars = history[i].Stars
+			i++
+		}
+		if sampled && i > 0 && i < len(history) {
+			stars = interpolateStars(history[i-1], history[i], at)
+		}
+		points = append(points, cu.StarPoint{Date: at, Stars: stars})
+	}
+	return points
+}
+
+func fetchStarHistory(ctx context.Context, owner, repo string, config *cu.Config, opts starHistoryOptions) (*cu.StarHistory, error) {
+	switch opts.Mode {
+	case starModeGraphQL:
+		return getStarHistoryGraphQL(ctx, owner, repo, config)
+	case starModeSample:
+		return getSampledStarHistory(ctx, owner, repo, config, opts.Samples)
+	default:
+		return getStarHistory(ctx, owner, repo, config)
+	}
+}
+
+func getStarHistoryGraphQL(ctx context.Context, owner, repo string, config *cu.Config) (*cu.StarHistory, error) {
+	var starTimes []time.Time
+	for starredAt, err := range clientFor(config).StarTimes(ctx, owner, repo) {
+		if err != nil {
+			return nil, err
+		}
+		starTimes = append(starTimes, starredAt)
+	}
+
+	return &cu.StarHistory{
+		RepoName: fmt.Sprintf("%s/%s", owner, repo),
+		History:  cumulativeStars(starTimes),
+	}, nil
+}
+
+// getSampledStarHistory fetches only the given number of stargazer pages.
+// The first star on page p is star number (p-1)*100+1, so every sampled
+// page contributes one exact point of the curve; the current star count
+// closes the series. Repositories small enough to fit in that many pages are
+// fetched in full instead.
+func getSampledStarHistory(ctx context.Context, owner, repo string, config *cu.Config, samples int) (*cu.StarHistory, error) {
+	client := clientFor(config)
+
+	repository, err := client.GetRepository(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(math.Ceil(float64(repository.StargazersCount) / stargazersPerPage))
+	if totalPages <= samples {
+		return getStarHistory(ctx, owner, repo, config)
+	}
+
+	pages := samplePages(min(totalPages, github.MaxStargazerPages), samples)
+	results := pool.Map(ctx, concurrency, pages, func(ctx context.Context, page int) ([]cu.StargazerResponse, error) {
+		return client.ListStargazersPage(ctx, owner, repo, page, stargazersPerPage)
+	})
+
+	history := make([]cu.StarPoint, 0, len(pages)+1)
+	for i, res := range results {
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		if len(res.Value) == 0 {
+			continue
+		}
+		history = append(history, cu.StarPoint{
+			Date:  res.Value[0].StarredAt,
+			Stars: (pages[i]-1)*stargazersPerPage + 1,
+		})
+	}
+	history = append(history, cu.StarPoint{Date: time.Now().UTC(), Stars: repository.StargazersCount})
+
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Date.Before(history[j].Date)
+	})
+
+	return &cu.StarHistory{
+		RepoName: fmt.Sprintf("%s/%s", owner, repo),
+		History:  history,
+		Sampled:  true,
+	}, nil
+}
+
+// samplePages spreads n page numbers evenly over 1..last, always including
+// the first and the last page
+func samplePages(last, n int) []int {
+	if n >= last {
+		pages := make([]int, last)
+		for i := range pages {
+			pages[i] = i + 1
+		}
+		return pages
+	}
+
+	pages := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		page := 1 + int(math.Round(float64(i)*float64(last-1)/float64(n-1)))
+		if len(pages) == 0 || pages[len(pages)-1] != page {
+			pages = append(pages, page)
+		}
+	}
+	return pages
+}
@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+func TestSamplePages(t *testing.T) {
+	tests := []struct {
+		last int
+		n    int
+		want []int
+	}{
+		{3, 5, []int{1, 2, 3}},
+		{10, 2, []int{1, 10}},
+		{400, 5, []int{1, 101, 201, 300, 400}},
+	}
+	for _, tt := range tests {
+		if got := samplePages(tt.last, tt.n); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("samplePages(%d, %d) = %v, want %v", tt.last, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestParseStarHistoryOptions(t *testing.T) {
+	tests := []struct {
+		query    string
+		mode     string
+		samples  int
+		hasError bool
+	}{
+		{"", starModeREST, defaultStarSamples, false},
+		{"mode=graphql", starModeGraphQL, defaultStarSamples, false},
+		{"mode=sample&samples=20", starModeSample, 20, false},
+		{"mode=magic", "", 0, true},
+		{"mode=sample&samples=1", "", 0, true},
+	}
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		opts, err := parseStarHistoryOptions(query)
+		if (err != nil) != tt.hasError {
+			t.Errorf("parseStarHistoryOptions(%q) error = %v, hasError %v", tt.query, err, tt.hasError)
+			continue
+		}
+		if !tt.hasError && (opts.Mode != tt.mode || opts.Samples != tt.samples) {
+			t.Errorf("parseStarHistoryOptions(%q) = %+v", tt.query, opts)
+		}
+	}
+}
+
+func TestHandleStarHistory_SampleMode(t *testing.T) {
+	const stars = 2050
+	var mu sync.Mutex
+	var pages []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/owner/repo" {
+			fmt.Fprintf(w, `{"stargazers_count":%d}`, stars)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pages = append(pages, page)
+		fmt.Fprintf(w, `[{"starred_at":"2020-01-%02dT00:00:00Z","user":{"login":"u"}}]`, page)
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+
+	req := httptest.NewRequest(http.MethodGet, "/star-history?repo=https://github.com/owner/repo&mode=sample&samples=3", nil)
+	rr := httptest.NewRecorder()
+	HandleStarHistory(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	sort.Ints(pages)
+	if !reflect.DeepEqual(pages, []int{1, 11, 21}) {
+		t.Errorf("Expected pages [1 11 21], got %v", pages)
+	}
+
+	var result struct {
+		Repositories []struct {
+			Sampled bool `json:"sampled"`
+			History []struct {
+				Stars int `json:"stars"`
+			} `json:"history"`
+		} `json:"repositories"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	history := result.Repositories[0].History
+	if !result.Repositories[0].Sampled || len(history) != 4 {
+		t.Fatalf("Expected 4 sampled points, got %+v", result.Repositories[0])
+	}
+	want := []int{1, 1001, 2001, stars}
+	for i, point := range history {
+		if point.Stars != want[i] {
+			t.Errorf("Point %d: expected %d stars, got %d", i, want[i], point.Stars)
+		}
+	}
+}
+
+func TestHandleStarHistory_PartialResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/owner/missing/stargazers" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[{"starred_at":"2020-01-01T00:00:00Z","user":{"login":"u"}}]`))
+	}))
+	defer server.Close()
+	useFakeGitHub(t, server)
+
+	req := httptest.NewRequest(http.MethodGet, "/star-history?repo=https://github.com/owner/repo&repo=https://github.com/owner/missing", nil)
+	rr := httptest.NewRecorder()
+	HandleStarHistory(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var result struct {
+		Repositories []struct {
+			RepoName string `json:"repo_name"`
+		} `json:"repositories"`
+		Errors []struct {
+			RepoName string `json:"repo_name"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if len(result.Repositories) != 1 || result.Repositories[0].RepoName != "owner/repo" {
+		t.Errorf("Expected owner/repo to succeed, got %+v", result.Repositories)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].RepoName != "owner/missing" {
+		t.Errorf("Expected owner/missing to fail, got %+v", result.Errors)
+	}
+}
+
+func TestParseStarHistoryOptions_Aggregation(t *testing.T) {
+	tests := []struct {
+		query    string
+		hasError bool
+	}{
+		{"interval=weekly", false},
+		{"points=50&series=new", false},
+		{"interval=monthly&series=cumulative", false},
+		{"interval=hourly", true},
+		{"points=1", true},
+		{"interval=daily&points=10", true},
+		{"series=new", true},
+		{"interval=daily&series=delta", true},
+	}
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		if _, err := parseStarHistoryOptions(query); (err != nil) != tt.hasError {
+			t.Errorf("parseStarHistoryOptions(%q) error = %v, hasError %v", tt.query, err, tt.hasError)
+		}
+	}
+}
+
+func TestAggregateStarHistory(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2024, 3, d, h, 0, 0, 0, time.UTC) }
+	perStar := func() *cu.StarHistory {
+		return &cu.StarHistory{History: []cu.StarPoint{
+			{Date: day(1, 1), Stars: 1},
+			{Date: day(1, 9), Stars: 2},
+			{Date: day(3, 5), Stars: 3},
+			{Date: day(3, 6), Stars: 4},
+			{Date: day(3, 7), Stars: 5},
+			{Date: day(5, 0), Stars: 6},
+		}}
+	}
+
+	tests := []struct {
+		name     string
+		opts     starHistoryOptions
+		expected []int
+	}{
+		{"daily cumulative", starHistoryOptions{Interval: intervalDaily, Series: seriesCumulative}, []int{2, 2, 5, 5, 6}},
+		{"daily new", starHistoryOptions{Interval: intervalDaily, Series: seriesNew}, []int{2, 0, 3, 0, 1}},
+		{"weekly", starHistoryOptions{Interval: intervalWeekly, Series: seriesCumulative}, []int{5, 6}},
+		{"fixed points", starHistoryOptions{Points: 3, Series: seriesCumulative}, []int{1, 2, 6}},
+		{"fixed points new", starHistoryOptions{Points: 3, Series: seriesNew}, []int{1, 1, 4}},
+		{"unaggregated", starHistoryOptions{Series: seriesCumulative}, []int{1, 2, 3, 4, 5, 6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := perStar()
+			aggregateStarHistory(history, tt.opts)
+			if len(history.History) != len(tt.expected) {
+				t.Fatalf("Expected %d points, got %+v", len(tt.expected), history.History)
+			}
+			for i, point := range history.History {
+				if point.Stars != tt.expected[i] {
+					t.Errorf("Expected %d stars at point %d, got %d", tt.expected[i], i, point.Stars)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregateStarHistory_Sampled(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	sampled := func() *cu.StarHistory {
+		return &cu.StarHistory{Sampled: true, History: []cu.StarPoint{
+			{Date: day(1), Stars: 1},
+			{Date: day(11), Stars: 1001},
+			{Date: day(21), Stars: 2001},
+		}}
+	}
+
+	history := sampled()
+	aggregateStarHistory(history, starHistoryOptions{Interval: intervalDaily, Series: seriesNew})
+	if len(history.History) != 21 {
+		t.Fatalf("Expected 21 daily points, got %d", len(history.History))
+	}
+	for i, point := range history.History[:20] {
+		if expected := []int{101, 100}[min(i, 1)]; point.Stars != expected {
+			t.Errorf("Expected %d new stars on day %d, got %d", expected, i+1, point.Stars)
+		}
+	}
+
+	history = sampled()
+	aggregateStarHistory(history, starHistoryOptions{Points: 5, Series: seriesCumulative})
+	var got []int
+	for _, point := range history.History {
+		got = append(got, point.Stars)
+	}
+	if !reflect.DeepEqual(got, []int{1, 501, 1001, 1501, 2001}) {
+		t.Errorf("Expected evenly interpolated points, got %v", got)
+	}
+}
+
+// serveRecordedStargazers replays the stargazer pages recorded under
+// testdata/stargazers/<repo>, linking each page to the next like GitHub.
+func serveRecordedStargazers(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) != 4 || parts[0] != "repos" || parts[3] != "stargazers" {
+			http.NotFound(w, r)
+			return
+		}
+		repo := parts[2]
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+
+		body, err := os.ReadFile(filepath.Join("testdata", "stargazers", repo, "page-"+page+".json"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		n, _ := strconv.Atoi(page)
+		if _, err := os.Stat(filepath.Join("testdata", "stargazers", repo, fmt.Sprintf("page-%d.json", n+1))); err == nil {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=%d&per_page=100>; rel="next"`, r.Host, r.URL.Path, n+1))
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	useFakeGitHub(t, server)
+	return server
+}
+
+func TestGetStarHistory_RecordedResponses(t *testing.T) {
+	serveRecordedStargazers(t)
+
+	tests := []struct {
+		repo          string
+		expectedStars int
+		expectedFirst time.Time
+		expectedLast  time.Time
+	}{
+		{"partial", 150, time.Date(2023, 5, 1, 8, 0, 0, 0, time.UTC), time.Date(2023, 6, 7, 14, 0, 0, 0, time.UTC)},
+		{"unordered", 3, time.Date(2023, 5, 1, 8, 0, 0, 0, time.UTC), time.Date(2023, 5, 3, 8, 0, 0, 0, time.UTC)},
+		{"empty", 0, time.Time{}, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repo, func(t *testing.T) {
+			history, err := getStarHistory(context.Background(), "keploy", tt.repo, nil)
+			if err != nil {
+				t.Fatalf("getStarHistory returned error: %v", err)
+			}
+			if history.RepoName != "keploy/"+tt.repo {
+				t.Errorf("Expected repo name keploy/%s, got %s", tt.repo, history.RepoName)
+			}
+			if len(history.History) != tt.expectedStars {
+				t.Fatalf("Expected %d points, got %d", tt.expectedStars, len(history.History))
+			}
+			for i, point := range history.History {
+				if point.Stars != i+1 {
+					t.Errorf("Expected point %d to hold %d stars, got %d", i, i+1, point.Stars)
+				}
+				if i > 0 && point.Date.Before(history.History[i-1].Date) {
+					t.Errorf("Expected point %d to be in date order", i)
+				}
+			}
+			if tt.expectedStars == 0 {
+				return
+			}
+			if first := history.History[0]; !first.Date.Equal(tt.expectedFirst) {
+				t.Errorf("Expected first star at %v, got %v", tt.expectedFirst, first.Date)
+			}
+			if last := history.History[len(history.History)-1]; !last.Date.Equal(tt.expectedLast) || last.Stars != tt.expectedStars {
+				t.Errorf("Expected %d stars at %v, got %+v", tt.expectedStars, tt.expectedLast, last)
+			}
+		})
+	}
+}
+
+func TestHandleStarHistory_RecordedPartialPage(t *testing.T) {
+	serveRecordedStargazers(t)
+
+	rr := httptest.NewRecorder()
+	HandleStarHistory(rr, httptest.NewRequest(http.MethodGet, "/star-history?repo=https://github.com/keploy/partial&repo=https://github.com/keploy/empty", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %v, got %v: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var result cu.MultiRepoStarHistory
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if len(result.Repositories) != 2 {
+		t.Fatalf("Expected 2 repositories, got %d", len(result.Repositories))
+	}
+	partial := result.Repositories[0].History
+	if last := partial[len(partial)-1].Stars; last != 150 {
+		t.Errorf("Expected the last point to hold 150 stars, got %d", last)
+	}
+	if empty := result.Repositories[1].History; len(empty) != 0 {
+		t.Errorf("Expected an empty history, got %d points", len(empty))
+	}
+}
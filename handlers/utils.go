@@ -1,9 +1,9 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"math"
 	"net/http"
@@ -12,6 +12,8 @@ import (
 	"time"
 
 	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/mailmap"
+	"github.com/keploy/gitstats/pool"
 )
 
 func calculateDownloadStats(releases []cu.Release) *cu.DownloadStats {
@@ -26,9 +28,12 @@ func calculateDownloadStats(releases []cu.Release) *cu.DownloadStats {
 
 	for _, release := range releases {
 		releaseStats := cu.ReleaseDownloadStats{
-			TagName:   release.TagName,
-			CreatedAt: release.CreatedAt,
-			Assets:    make([]cu.AssetStats, 0),
+			TagName:     release.TagName,
+			CreatedAt:   release.CreatedAt,
+			PublishedAt: release.PublishedAt,
+			Draft:       release.Draft,
+			Prerelease:  release.Prerelease,
+			Assets:      make([]cu.AssetStats, 0),
 		}
 
 		for _, asset := range release.Assets {
@@ -47,412 +52,125 @@ func calculateDownloadStats(releases []cu.Release) *cu.DownloadStats {
 	return stats
 }
 
-func getAllReleases(owner, repo string, config *cu.Config) ([]cu.Release, error) {
-	var allReleases []cu.Release
-	page := 1
-	perPage := 100
-
-	for {
-		url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases?page=%d&per_page=%d",
-			owner, repo, page, perPage)
-
-		client := &http.Client{}
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, fmt.Errorf("error creating request: %v", err)
-		}
-
-		// Add headers conditionally
-		req.Header.Add("Accept", "application/vnd.github.v3+json")
-		if config != nil && config.GithubToken != "" {
-			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", config.GithubToken))
-		}
-
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("error making request: %v", err)
-		}
-
-		// Check rate limit headers
-		remaining := resp.Header.Get("X-RateLimit-Remaining")
-		limit := resp.Header.Get("X-RateLimit-Limit")
-
-		if resp.StatusCode == http.StatusForbidden {
-			resp.Body.Close()
-			return nil, fmt.Errorf("rate limit exceeded. Please use a GitHub token. Limit: %s, Remaining: %s", limit, remaining)
-		}
-
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			return nil, fmt.Errorf("GitHub API returned status: %d, body: %s", resp.StatusCode, string(body))
-		}
-
-		var releases []cu.Release
-		if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
-			resp.Body.Close()
-			return nil, fmt.Errorf("error decoding response: %v", err)
-		}
-		resp.Body.Close()
-
-		if len(releases) == 0 {
-			break
-		}
-
-		allReleases = append(allReleases, releases...)
-
-		if len(releases) < perPage {
-			break
-		}
-
-		page++
-	}
-
-	return allReleases, nil
+func getAllReleases(ctx context.Context, owner, repo string, config *cu.Config) ([]cu.Release, error) {
+	return clientFor(config).ListReleases(ctx, owner, repo)
 }
 
 // extractRepoInfo extracts owner and repo name from GitHub URL
 func extractRepoInfo(repoURL string) (string, string, error) {
-	patterns := []string{
-		`github\.com[:/]([^/]+)/([^/\.]+)(?:\.git)?$`,
-		`github\.com/([^/]+)/([^/\.]+)/?$`,
-	}
-
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		matches := re.FindStringSubmatch(repoURL)
-		if len(matches) == 3 {
-			return matches[1], matches[2], nil
-		}
-	}
-
-	return "", "", fmt.Errorf("invalid GitHub repository URL")
+	_, owner, repo, err := parseRepoURL(repoURL)
+	return owner, repo, err
 }
-func getStarHistory(owner, repo string, config *cu.Config) (*cu.StarHistory, error) {
-	// GitHub's API doesn't provide direct star history, so we'll use stargazers endpoint
-	page := 1
-	perPage := 100
-	history := make([]cu.StarPoint, 0)
-
-	for {
-		url := fmt.Sprintf("https://api.github.com/repos/%s/%s/stargazers?page=%d&per_page=%d",
-			owner, repo, page, perPage)
 
-		client := &http.Client{}
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, fmt.Errorf("error creating request: %v", err)
-		}
-
-		// Required header for timestamp information
-		req.Header.Add("Accept", "application/vnd.github.v3.star+json")
-		if config != nil && config.GithubToken != "" {
-			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", config.GithubToken))
-		}
-
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("error making request: %v", err)
-		}
+// parseRepoURL extracts host, owner and repo name from a repository URL on
+// public GitHub or on any registered GitHub Enterprise Server host
+func parseRepoURL(repoURL string) (string, string, string, error) {
+	hosts := make([]string, 0, len(hostClients)+1)
+	for host := range hostClients {
+		hosts = append(hosts, host)
+	}
+	// Longer hosts first, so github.example.com is not mistaken for github.com
+	sort.Slice(hosts, func(i, j int) bool { return len(hosts[i]) > len(hosts[j]) })
+	hosts = append(hosts, publicHost)
 
-		if resp.StatusCode == http.StatusForbidden {
-			resp.Body.Close()
-			return nil, fmt.Errorf("rate limit exceeded. Please use a GitHub token")
+	for _, host := range hosts {
+		patterns := []string{
+			`(?i)` + regexp.QuoteMeta(host) + `[:/]([^/]+)/([^/\.]+)(?:\.git)?$`,
+			`(?i)` + regexp.QuoteMeta(host) + `/([^/]+)/([^/\.]+)/?$`,
 		}
 
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			return nil, fmt.Errorf("GitHub API returned status: %d, body: %s", resp.StatusCode, string(body))
+		for _, pattern := range patterns {
+			re := regexp.MustCompile(pattern)
+			matches := re.FindStringSubmatch(repoURL)
+			if len(matches) == 3 {
+				return host, matches[1], matches[2], nil
+			}
 		}
+	}
 
-		// Parse response
-		var stargazers []struct {
-			StarredAt time.Time `json:"starred_at"`
-		}
+	return "", "", "", fmt.Errorf("invalid GitHub repository URL")
+}
 
-		if err := json.NewDecoder(resp.Body).Decode(&stargazers); err != nil {
-			resp.Body.Close()
-			return nil, fmt.Errorf("error decoding response: %v", err)
-		}
-		resp.Body.Close()
+// repoRef identifies one repository of a request that covers several.
+type repoRef struct {
+	Owner  string
+	Repo   string
+	Config *cu.Config
+}
 
-		if len(stargazers) == 0 {
-			break
-		}
+// Name returns the owner/repo form of the reference.
+func (r repoRef) Name() string {
+	return r.Owner + "/" + r.Repo
+}
 
-		// Accumulate star counts
-		starCount := page * perPage
-		for i, sg := range stargazers {
-			history = append(history, cu.StarPoint{
-				Date:  sg.StarredAt,
-				Stars: starCount - (len(stargazers) - i - 1),
-			})
-		}
+// Key identifies the repository across hosts: owner/repo on public GitHub,
+// host/owner/repo on GitHub Enterprise Server.
+func (r repoRef) Key() string {
+	if r.Config == nil || r.Config.Host == "" || r.Config.Host == publicHost {
+		return r.Name()
+	}
+	return r.Config.Host + "/" + r.Name()
+}
 
-		if len(stargazers) < perPage {
-			break
+func getStarHistory(ctx context.Context, owner, repo string, config *cu.Config) (*cu.StarHistory, error) {
+	// GitHub's API doesn't provide direct star history, so we'll use stargazers endpoint
+	var starTimes []time.Time
+	for sg, err := range clientFor(config).Stargazers(ctx, owner, repo) {
+		if err != nil {
+			return nil, err
 		}
-
-		page++
+		starTimes = append(starTimes, sg.StarredAt)
 	}
 
-	// Sort history by date
-	sort.Slice(history, func(i, j int) bool {
-		return history[i].Date.Before(history[j].Date)
-	})
-
 	return &cu.StarHistory{
 		RepoName: fmt.Sprintf("%s/%s", owner, repo),
-		History:  history,
+		History:  cumulativeStars(starTimes),
 	}, nil
 }
 
-func getOrgContributors(org string, config *cu.Config) (*cu.OrganizationStats, error) {
-	page := 1
-	perPage := 100
-	totalContributors := make(map[string]struct{})
-	totalRepos := 0
-
-	for {
-		url := fmt.Sprintf("https://api.github.com/orgs/%s/repos?page=%d&per_page=%d", org, page, perPage)
-
-		client := &http.Client{}
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, fmt.Errorf("error creating request: %v", err)
-		}
-
-		req.Header.Add("Accept", "application/vnd.github.v3+json")
-		if config != nil && config.GithubToken != "" {
-			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", config.GithubToken))
-		}
-
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("error making request: %v", err)
-		}
-
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			return nil, fmt.Errorf("GitHub API returned status: %d, body: %s", resp.StatusCode, string(body))
-		}
-
-		var repos []map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
-			resp.Body.Close()
-			return nil, fmt.Errorf("error decoding response: %v", err)
-		}
-		resp.Body.Close()
-
-		if len(repos) == 0 {
-			break
-		}
-
-		totalRepos += len(repos)
-
-		for _, repo := range repos {
-			repoName := repo["name"].(string)
-			repoContributorsURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/contributors", org, repoName)
-
-			contribReq, err := http.NewRequest("GET", repoContributorsURL, nil)
-			if err != nil {
-				return nil, fmt.Errorf("error creating contributors request: %v", err)
-			}
-
-			contribReq.Header.Add("Accept", "application/vnd.github.v3+json")
-			if config != nil && config.GithubToken != "" {
-				contribReq.Header.Add("Authorization", fmt.Sprintf("Bearer %s", config.GithubToken))
-			}
-
-			contribResp, err := client.Do(contribReq)
-			if err != nil {
-				return nil, fmt.Errorf("error making contributors request: %v", err)
-			}
-
-			if contribResp.StatusCode == http.StatusNoContent {
-				// Repository has no contributors, continue to the next one
-				contribResp.Body.Close()
-				continue
-			}
-
-			if contribResp.StatusCode != http.StatusOK {
-				body, _ := io.ReadAll(contribResp.Body)
-				contribResp.Body.Close()
-				return nil, fmt.Errorf("GitHub API returned status for contributors: %d, body: %s", contribResp.StatusCode, string(body))
-			}
-
-			var contributors []cu.Contributor
-			if err := json.NewDecoder(contribResp.Body).Decode(&contributors); err != nil {
-				contribResp.Body.Close()
-				return nil, fmt.Errorf("error decoding contributors response: %v", err)
-			}
-			contribResp.Body.Close()
-
-			for _, contributor := range contributors {
-				totalContributors[contributor.Login] = struct{}{}
-			}
-		}
+// cumulativeStars turns the times of individual stars into a history with
+// one point per star. The times are sorted before counting, so the n-th
+// point holds n stars whatever order the API listed them in.
+func cumulativeStars(starTimes []time.Time) []cu.StarPoint {
+	sort.Slice(starTimes, func(i, j int) bool {
+		return starTimes[i].Before(starTimes[j])
+	})
 
-		page++
+	history := make([]cu.StarPoint, 0, len(starTimes))
+	for i, starredAt := range starTimes {
+		history = append(history, cu.StarPoint{Date: starredAt, Stars: i + 1})
 	}
-
-	return &cu.OrganizationStats{
-		OrgName:           org,
-		TotalRepos:        totalRepos,
-		TotalContributors: len(totalContributors),
-	}, nil
+	return history
 }
 
-func getOrgMembers(org string) (map[string]struct{}, error) {
+func getOrgMembers(ctx context.Context, org string, config *cu.Config) (map[string]struct{}, error) {
 	members := make(map[string]struct{})
-	page := 1
-	perPage := 100
-
-	for {
-		url := fmt.Sprintf("https://api.github.com/orgs/%s/members?page=%d&per_page=%d",
-			org, page, perPage)
-
-		client := &http.Client{}
-		req, err := http.NewRequest("GET", url, nil)
+	for member, err := range clientFor(config).OrgMembers(ctx, org) {
 		if err != nil {
-			return nil, fmt.Errorf("error creating request: %v", err)
-		}
-
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("error making request: %v", err)
-		}
-
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			return nil, fmt.Errorf("GitHub API returned status: %d, body: %s", resp.StatusCode, string(body))
-		}
-
-		var orgMembers []struct {
-			Login string `json:"login"`
+			return nil, err
 		}
-
-		if err := json.NewDecoder(resp.Body).Decode(&orgMembers); err != nil {
-			resp.Body.Close()
-			return nil, fmt.Errorf("error decoding response: %v", err)
-		}
-		resp.Body.Close()
-
-		if len(orgMembers) == 0 {
-			break
-		}
-
-		for _, member := range orgMembers {
-			members[member.Login] = struct{}{}
-		}
-
-		if len(orgMembers) < perPage {
-			break
-		}
-
-		page++
+		members[member.Login] = struct{}{}
 	}
 
 	return members, nil
 }
 
-func getRecentCommits(owner, repo string, since time.Time, config *cu.Config) ([]struct {
-	Author struct {
-		Login string `json:"login"`
-	} `json:"author"`
-	Commit struct {
-		Author struct {
-			Date time.Time `json:"date"`
-		} `json:"author"`
-	} `json:"commit"`
-}, error) {
-	page := 1
-	perPage := 100
-	var allCommits []struct {
-		Author struct {
-			Login string `json:"login"`
-		} `json:"author"`
-		Commit struct {
-			Author struct {
-				Date time.Time `json:"date"`
-			} `json:"author"`
-		} `json:"commit"`
-	}
-
-	for {
-		url := fmt.Sprintf("https://api.github.com/repos/%s/%s/commits?since=%s&page=%d&per_page=%d",
-			owner, repo, since.Format(time.RFC3339), page, perPage)
-
-		client := &http.Client{}
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, fmt.Errorf("error creating request: %v", err)
-		}
-
-		req.Header.Add("Accept", "application/vnd.github.v3+json")
-		if config != nil && config.GithubToken != "" {
-			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", config.GithubToken))
-		}
-
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("error making request: %v", err)
-		}
-
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			return nil, fmt.Errorf("GitHub API returned status: %d, body: %s", resp.StatusCode, string(body))
-		}
-
-		var commits []struct {
-			Author struct {
-				Login string `json:"login"`
-			} `json:"author"`
-			Commit struct {
-				Author struct {
-					Date time.Time `json:"date"`
-				} `json:"author"`
-			} `json:"commit"`
-		}
-
-		if err := json.NewDecoder(resp.Body).Decode(&commits); err != nil {
-			resp.Body.Close()
-			return nil, fmt.Errorf("error decoding response: %v", err)
-		}
-		resp.Body.Close()
-
-		if len(commits) == 0 {
-			break
-		}
-
-		allCommits = append(allCommits, commits...)
-
-		if len(commits) < perPage {
-			break
-		}
-
-		page++
-	}
-
-	return allCommits, nil
+func getRecentCommits(ctx context.Context, owner, repo string, since time.Time, config *cu.Config) ([]cu.Commit, error) {
+	return clientFor(config).ListCommits(ctx, owner, repo, since)
 }
 
-func handleOrganization(w http.ResponseWriter, orgName string, config *cu.Config) {
+func handleOrganization(ctx context.Context, w http.ResponseWriter, orgName string, config *cu.Config, filter contributorFilter) {
 	// Get organization members to exclude them
-	orgMembers, err := getOrgMembers(orgName)
+	orgMembers, err := getOrgMembers(ctx, orgName, config)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeFetchError(w, config, err)
 		return
 	}
 
 	// Get all repositories in the organization
-	repos, err := getOrgRepositories(orgName, config)
+	repos, err := getOrgRepositories(ctx, orgName, config)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeFetchError(w, config, err)
 		return
 	}
 
@@ -460,129 +178,98 @@ func handleOrganization(w http.ResponseWriter, orgName string, config *cu.Config
 	contributorStats := make(map[string]*cu.ActiveContributor)
 
 	// Collect commits from all repositories
-	for _, repo := range repos {
-		commits, err := getRecentCommits(orgName, repo.Name, since, config)
-		if err != nil {
+	results := pool.Map(ctx, concurrency, repos, func(ctx context.Context, repo cu.Repository) ([]cu.Commit, error) {
+		return getRecentCommits(ctx, orgName, repo.Name, since, config)
+	})
+
+	if err := ctx.Err(); err != nil {
+		writeFetchError(w, config, err)
+		return
+	}
+
+	var repoErrors []cu.RepoError
+	for i, res := range results {
+		if res.Err != nil {
 			// Log the error but continue with other repositories
-			log.Printf("Error getting commits for %s/%s: %v", orgName, repo.Name, err)
+			log.Printf("Error getting commits for %s/%s: %v", orgName, repos[i].Name, res.Err)
+			repoErrors = append(repoErrors, cu.RepoError{
+				RepoName: fmt.Sprintf("%s/%s", orgName, repos[i].Name),
+				Error:    res.Err.Error(),
+			})
 			continue
 		}
 
-		processCommits(commits, orgMembers, contributorStats)
+		processCommits(res.Value, orgMembers, contributorStats, filter)
 	}
 
-	responseData := prepareResponse(contributorStats, orgName, "")
+	responseData := prepareResponse(contributorStats, orgName, "", filter)
+	responseData.Errors = repoErrors
+	writeRateLimitHeaders(w, config)
 	sendJSONResponse(w, responseData)
 }
 
-func handleSingleRepo(w http.ResponseWriter, owner, repo string, config *cu.Config) {
-	orgMembers, err := getOrgMembers(owner)
+func handleSingleRepo(ctx context.Context, w http.ResponseWriter, owner, repo string, config *cu.Config, filter contributorFilter) {
+	orgMembers, err := getOrgMembers(ctx, owner, config)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeFetchError(w, config, err)
 		return
 	}
 
 	since := time.Now().AddDate(0, 0, -30)
-	commits, err := getRecentCommits(owner, repo, since, config)
+	commits, err := getRecentCommits(ctx, owner, repo, since, config)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeFetchError(w, config, err)
 		return
 	}
 
 	contributorStats := make(map[string]*cu.ActiveContributor)
-	processCommits(commits, orgMembers, contributorStats)
+	processCommits(commits, orgMembers, contributorStats, filter)
 
-	responseData := prepareResponse(contributorStats, owner, repo)
+	responseData := prepareResponse(contributorStats, owner, repo, filter)
+	writeRateLimitHeaders(w, config)
 	sendJSONResponse(w, responseData)
 }
 
-func getOrgRepositories(org string, config *cu.Config) ([]struct {
-	Name string `json:"name"`
-}, error) {
-	page := 1
-	perPage := 100
-	var allRepos []struct {
-		Name string `json:"name"`
-	}
-
-	for {
-		url := fmt.Sprintf("https://api.github.com/orgs/%s/repos?page=%d&per_page=%d&type=public",
-			org, page, perPage)
-
-		client := &http.Client{}
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, fmt.Errorf("error creating request: %v", err)
-		}
-
-		if config != nil && config.GithubToken != "" {
-			req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", config.GithubToken))
-		}
-
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("error making request: %v", err)
-		}
-
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			return nil, fmt.Errorf("GitHub API returned status: %d, body: %s", resp.StatusCode, string(body))
-		}
-
-		var repos []struct {
-			Name string `json:"name"`
-		}
-
-		if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
-			resp.Body.Close()
-			return nil, fmt.Errorf("error decoding response: %v", err)
-		}
-		resp.Body.Close()
-
-		if len(repos) == 0 {
-			break
-		}
-
-		allRepos = append(allRepos, repos...)
-
-		if len(repos) < perPage {
-			break
-		}
-
-		page++
-	}
-
-	return allRepos, nil
+func getOrgRepositories(ctx context.Context, org string, config *cu.Config) ([]cu.Repository, error) {
+	return clientFor(config).ListOrgRepos(ctx, org, "public")
 }
 
-func processCommits(commits []struct {
-	Author struct {
-		Login string `json:"login"`
-	} `json:"author"`
-	Commit struct {
-		Author struct {
-			Date time.Time `json:"date"`
-		} `json:"author"`
-	} `json:"commit"`
-}, orgMembers map[string]struct{}, contributorStats map[string]*cu.ActiveContributor) {
+// processCommits counts the commits of every author who is not a member of
+// the organization and marks bots. Authors are merged by the mailmap;
+// commits without a GitHub login are only counted with filter.Anon.
+func processCommits(commits []cu.Commit, orgMembers map[string]struct{}, contributorStats map[string]*cu.ActiveContributor, filter contributorFilter) {
 	for _, commit := range commits {
-		if commit.Author.Login == "" {
+		if commit.Author.Login == "" && !filter.Anon {
 			continue
 		}
 
+		key := identities.Resolve(mailmap.Identity{
+			Login: commit.Author.Login,
+			Name:  commit.Commit.Author.Name,
+			Email: commit.Commit.Author.Email,
+		})
 		if _, isOrgMember := orgMembers[commit.Author.Login]; isOrgMember {
 			continue
 		}
+		if _, isOrgMember := orgMembers[key]; isOrgMember {
+			continue
+		}
 
-		stats, exists := contributorStats[commit.Author.Login]
+		stats, exists := contributorStats[key]
 		if !exists {
 			stats = &cu.ActiveContributor{
-				Login:          commit.Author.Login,
+				Login:          key,
 				Contributions:  0,
 				LastActiveDate: commit.Commit.Author.Date,
+				Anonymous:      true,
 			}
-			contributorStats[commit.Author.Login] = stats
+			contributorStats[key] = stats
+		}
+		if commit.Author.Login != "" {
+			stats.Anonymous = false
+		}
+		if isBot(commit.Author.Login, commit.Commit.Author.Name, commit.Author.Type) {
+			stats.Bot = true
 		}
 
 		stats.Contributions++
@@ -592,10 +279,18 @@ func processCommits(commits []struct {
 	}
 }
 
-func prepareResponse(contributorStats map[string]*cu.ActiveContributor, owner, repo string) cu.ActiveContributorsResponse {
+// prepareResponse lists the contributors passing the bot mode of filter,
+// most active first, and summarizes the bots among all of them
+func prepareResponse(contributorStats map[string]*cu.ActiveContributor, owner, repo string, filter contributorFilter) cu.ActiveContributorsResponse {
 	var activeContributors []cu.ActiveContributor
+	botActivity := make(map[string]*cu.BotActivity)
 	for _, stats := range contributorStats {
-		activeContributors = append(activeContributors, *stats)
+		if stats.Bot {
+			botActivity[stats.Login] = &cu.BotActivity{Login: stats.Login, Contributions: stats.Contributions}
+		}
+		if filter.counts(stats.Bot) {
+			activeContributors = append(activeContributors, *stats)
+		}
 	}
 
 	sort.Slice(activeContributors, func(i, j int) bool {
@@ -614,6 +309,7 @@ func prepareResponse(contributorStats map[string]*cu.ActiveContributor, owner, r
 		RepoName:           name,
 		TimeRange:          "Last 30 days",
 		ActiveContributors: activeContributors,
+		Bots:               summarizeBots(botActivity),
 	}
 }
 
@@ -622,29 +318,15 @@ func sendJSONResponse(w http.ResponseWriter, response cu.ActiveContributorsRespo
 	json.NewEncoder(w).Encode(response)
 }
 
-func fetchStargazers(owner, repo, token string, page int) ([]cu.Stargazer, bool, int, error) {
+func fetchStargazers(ctx context.Context, owner, repo string, config *cu.Config, page int) ([]cu.Stargazer, bool, int, error) {
 	perPage := 100
-	client := &http.Client{}
+	client := clientFor(config)
 
 	// First, get total stargazer count
-	repoURL := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
-	repoReq, err := http.NewRequest("GET", repoURL, nil)
-	if err != nil {
-		return nil, false, 0, err
-	}
-	repoReq.Header.Add("Authorization", "Bearer "+token)
-	repoResp, err := client.Do(repoReq)
+	repoData, err := client.GetRepository(ctx, owner, repo)
 	if err != nil {
 		return nil, false, 0, err
 	}
-	defer repoResp.Body.Close()
-
-	var repoData struct {
-		StargazersCount int `json:"stargazers_count"`
-	}
-	if err := json.NewDecoder(repoResp.Body).Decode(&repoData); err != nil {
-		return nil, false, 0, err
-	}
 
 	// Calculate the correct page number from the end
 	totalPages := int(math.Ceil(float64(repoData.StargazersCount) / float64(perPage)))
@@ -654,36 +336,15 @@ func fetchStargazers(owner, repo, token string, page int) ([]cu.Stargazer, bool,
 	}
 
 	// Fetch stargazers for the requested reverse page
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/stargazers?page=%d&per_page=%d",
-		owner, repo, reversePage, perPage)
-
-	req, err := http.NewRequest("GET", url, nil)
+	starResponses, err := client.ListStargazersPage(ctx, owner, repo, reversePage, perPage)
 	if err != nil {
 		return nil, false, 0, err
 	}
 
-	req.Header.Add("Accept", "application/vnd.github.v3.star+json")
-	req.Header.Add("Authorization", "Bearer "+token)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, false, 0, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, false, 0, fmt.Errorf("GitHub API returned status: %d", resp.StatusCode)
-	}
-
-	var starResponses []cu.StargazerResponse
-	if err := json.NewDecoder(resp.Body).Decode(&starResponses); err != nil {
-		return nil, false, 0, err
-	}
-
 	// Fetch additional user details for each stargazer
 	var stargazers []cu.Stargazer
 	for _, sr := range starResponses {
-		user, err := fetchUserDetails(sr.User.Login, token)
+		user, err := fetchUserDetails(ctx, sr.User.Login, config)
 		if err != nil {
 			log.Printf("Error fetching details for user %s: %v", sr.User.Login, err)
 			continue
@@ -709,31 +370,6 @@ func fetchStargazers(owner, repo, token string, page int) ([]cu.Stargazer, bool,
 	return stargazers, hasMore, repoData.StargazersCount, nil
 }
 
-func fetchUserDetails(username, token string) (*cu.User, error) {
-	client := &http.Client{}
-	url := fmt.Sprintf("https://api.github.com/users/%s", username)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Add("Authorization", "Bearer "+token)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status: %d", resp.StatusCode)
-	}
-
-	var user cu.User
-	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
-		return nil, err
-	}
-
-	return &user, nil
+func fetchUserDetails(ctx context.Context, username string, config *cu.Config) (*cu.User, error) {
+	return clientFor(config).GetUser(ctx, username)
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/github"
 )
 
 // Mock HTTP client and server setup for testing
@@ -77,3 +78,48 @@ func TestExtractRepoInfo_InvalidURL(t *testing.T) {
 		t.Errorf("Expected error for invalid URL, got %v", err)
 	}
 }
+
+func TestParseRepoURL_EnterpriseHost(t *testing.T) {
+	SetHostClient("ghe.example.com", github.NewClient(github.WithBaseURL("https://ghe.example.com/api/v3")))
+	t.Cleanup(func() { delete(hostClients, "ghe.example.com") })
+
+	tests := []struct {
+		url      string
+		host     string
+		owner    string
+		repo     string
+		hasError bool
+	}{
+		{"https://github.com/keploy/keploy", "github.com", "keploy", "keploy", false},
+		{"https://ghe.example.com/platform/api", "ghe.example.com", "platform", "api", false},
+		{"git@ghe.example.com:platform/api.git", "ghe.example.com", "platform", "api", false},
+		{"https://ghe.other.com/platform/api", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		host, owner, repo, err := parseRepoURL(tt.url)
+		if (err != nil) != tt.hasError {
+			t.Errorf("parseRepoURL(%s) error = %v, hasError %v", tt.url, err, tt.hasError)
+			continue
+		}
+		if host != tt.host || owner != tt.owner || repo != tt.repo {
+			t.Errorf("parseRepoURL(%s) = %s, %s, %s, want %s, %s, %s", tt.url, host, owner, repo, tt.host, tt.owner, tt.repo)
+		}
+	}
+}
+
+func TestClientFor_SelectsHost(t *testing.T) {
+	ghe := github.NewClient(github.WithBaseURL("https://ghe.example.com/api/v3"))
+	SetHostClient("ghe.example.com", ghe)
+	t.Cleanup(func() { delete(hostClients, "ghe.example.com") })
+
+	if got := clientFor(&cu.Config{Host: "ghe.example.com"}).BaseURL(); got != "https://ghe.example.com/api/v3/" {
+		t.Errorf("Expected enterprise API root, got %s", got)
+	}
+	if got := clientFor(&cu.Config{Host: "github.com"}).BaseURL(); got != githubClient.BaseURL() {
+		t.Errorf("Expected public API root, got %s", got)
+	}
+	if got := clientFor(nil).BaseURL(); got != githubClient.BaseURL() {
+		t.Errorf("Expected public API root, got %s", got)
+	}
+}
@@ -0,0 +1,156 @@
+// Package mailmap merges the identities one person contributes under, in
+// the spirit of git's .mailmap.
+//
+// Each non-empty line of an alias file lists the identities of one person:
+// GitHub logins written as @login, commit emails written as <email>, and
+// optionally a name before an email. Lines starting with # are comments.
+//
+//	Alice Doe <alice@example.com> <alice@old-job.com> @alice-work
+//	@bob <bob@users.noreply.github.com>
+//
+// Every login and email on a line resolves to the same canonical identity:
+// the first login on the line, or else the first name, or else the first
+// email. Logins and emails match case-insensitively; names are only used
+// for display.
+package mailmap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Identity is what GitHub reports about the author of a contribution. Any
+// of the fields may be empty, e.g. the login of commits made with an email
+// not linked to a GitHub account.
+type Identity struct {
+	Login, Name, Email string
+}
+
+// Mailmap resolves identities to canonical names. The zero value and a nil
+// Mailmap merge nothing.
+type Mailmap struct {
+	logins map[string]string
+	emails map[string]string
+}
+
+// Parse reads an alias file.
+func Parse(r io.Reader) (*Mailmap, error) {
+	m := &Mailmap{logins: make(map[string]string), emails: make(map[string]string)}
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		logins, names, emails, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNo, err)
+		}
+		if len(logins)+len(emails) == 0 {
+			return nil, fmt.Errorf("line %d: expected a login or an email", lineNo)
+		}
+
+		var canonical string
+		switch {
+		case len(logins) > 0:
+			canonical = logins[0]
+		case len(names) > 0:
+			canonical = names[0]
+		default:
+			canonical = emails[0]
+		}
+		for _, login := range logins {
+			m.logins[strings.ToLower(login)] = canonical
+		}
+		for _, email := range emails {
+			m.emails[strings.ToLower(email)] = canonical
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parseLine splits a line into its logins, names and emails. Text outside
+// angle brackets that is not a login is the name of the following email.
+func parseLine(line string) (logins, names, emails []string, err error) {
+	var name []string
+	for line != "" {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "<"):
+			end := strings.IndexByte(line, '>')
+			if end < 0 {
+				return nil, nil, nil, fmt.Errorf("unterminated email in %q", line)
+			}
+			email := strings.TrimSpace(line[1:end])
+			if email == "" {
+				return nil, nil, nil, fmt.Errorf("empty email")
+			}
+			emails = append(emails, email)
+			if len(name) > 0 {
+				names = append(names, strings.Join(name, " "))
+				name = nil
+			}
+			line = line[end+1:]
+		default:
+			word, rest, _ := strings.Cut(line, " ")
+			if before, _, found := strings.Cut(word, "<"); found {
+				word, rest = before, line[len(before):]
+			}
+			if strings.HasPrefix(word, "@") && len(word) > 1 {
+				logins = append(logins, word[1:])
+			} else if word != "" {
+				name = append(name, word)
+			}
+			line = rest
+		}
+	}
+	if len(name) > 0 {
+		return nil, nil, nil, fmt.Errorf("name %q is not followed by an email", strings.Join(name, " "))
+	}
+	return logins, names, emails, nil
+}
+
+// Load reads the alias file at path.
+func Load(path string) (*Mailmap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading mailmap: %v", err)
+	}
+	defer f.Close()
+
+	m, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing mailmap: %v", err)
+	}
+	return m, nil
+}
+
+// Resolve returns the canonical name of an identity. Identities the
+// mailmap does not know are named by their login, or else by their
+// lower-cased email, or else by their name.
+func (m *Mailmap) Resolve(id Identity) string {
+	if m != nil {
+		if canonical, ok := m.logins[strings.ToLower(id.Login)]; ok && id.Login != "" {
+			return canonical
+		}
+		if canonical, ok := m.emails[strings.ToLower(id.Email)]; ok && id.Email != "" {
+			return canonical
+		}
+	}
+	switch {
+	case id.Login != "":
+		return id.Login
+	case id.Email != "":
+		return strings.ToLower(id.Email)
+	default:
+		return id.Name
+	}
+}
@@ -0,0 +1,61 @@
+package mailmap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	m, err := Parse(strings.NewReader(`
+# Aliases of the keploy team
+Alice Doe <alice@example.com> <Alice@Old-Job.com> @alice-work
+@bob <bob@users.noreply.github.com> @bobby
+Carol <carol@example.com>
+<dave@example.com> <dave@laptop.local>
+`))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	tests := []struct {
+		id       Identity
+		expected string
+	}{
+		{Identity{Email: "alice@old-job.com"}, "alice-work"},
+		{Identity{Name: "Alice", Email: "ALICE@example.com"}, "alice-work"},
+		{Identity{Login: "Alice-Work"}, "alice-work"},
+		{Identity{Login: "bobby"}, "bob"},
+		{Identity{Name: "Bob", Email: "bob@users.noreply.github.com"}, "bob"},
+		{Identity{Email: "carol@example.com"}, "Carol"},
+		{Identity{Email: "dave@laptop.local"}, "dave@example.com"},
+		{Identity{Login: "erin", Email: "alice@example.com"}, "alice-work"},
+		{Identity{Login: "erin"}, "erin"},
+		{Identity{Name: "Frank", Email: "Frank@Example.com"}, "frank@example.com"},
+		{Identity{Name: "Grace"}, "Grace"},
+	}
+	for _, tt := range tests {
+		if got := m.Resolve(tt.id); got != tt.expected {
+			t.Errorf("Resolve(%+v): expected %q, got %q", tt.id, tt.expected, got)
+		}
+	}
+}
+
+func TestResolve_NilMailmap(t *testing.T) {
+	var m *Mailmap
+	if got := m.Resolve(Identity{Login: "alice", Email: "alice@example.com"}); got != "alice" {
+		t.Errorf("Expected the login, got %q", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, line := range []string{
+		"Alice Doe",
+		"Alice <alice@example.com",
+		"Alice <>",
+		"<alice@example.com> Alice Doe",
+	} {
+		if _, err := Parse(strings.NewReader(line)); err == nil {
+			t.Errorf("Expected an error parsing %q", line)
+		}
+	}
+}
@@ -1,13 +1,98 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"slices"
 
+	"github.com/keploy/gitstats/assets"
+	"github.com/keploy/gitstats/bots"
+	cu "github.com/keploy/gitstats/common"
+	"github.com/keploy/gitstats/github"
+	handler "github.com/keploy/gitstats/handlers"
+	"github.com/keploy/gitstats/mailmap"
 	routes "github.com/keploy/gitstats/routes"
+	"github.com/keploy/gitstats/store"
 )
 
 func main() {
+	config := cu.LoadServerConfig()
+	handler.Configure(config)
+
+	client, err := newGitHubClient(config, config.GitHubAPIURL)
+	if err != nil {
+		log.Fatalf("Error configuring GitHub client: %v", err)
+	}
+	handler.SetGitHubClient(client)
+
+	for host, apiURL := range config.EnterpriseHosts {
+		client, err := newGitHubClient(config, apiURL)
+		if err != nil {
+			log.Fatalf("Error configuring GitHub client for %s: %v", host, err)
+		}
+		handler.SetHostClient(host, client)
+		log.Printf("Serving GitHub Enterprise host %s via %s", host, apiURL)
+	}
+
+	if config.AssetRulesFile != "" {
+		rules, err := assets.LoadRules(config.AssetRulesFile)
+		if err != nil {
+			log.Fatalf("Error loading asset rules: %v", err)
+		}
+		classifier, err := assets.NewClassifier(rules)
+		if err != nil {
+			log.Fatalf("Error loading asset rules: %v", err)
+		}
+		handler.SetAssetClassifier(classifier)
+	}
+
+	if config.BotRulesFile != "" {
+		rules, err := bots.LoadRules(config.BotRulesFile)
+		if err != nil {
+			log.Fatalf("Error loading bot rules: %v", err)
+		}
+		classifier, err := bots.NewClassifier(rules)
+		if err != nil {
+			log.Fatalf("Error loading bot rules: %v", err)
+		}
+		handler.SetBotClassifier(classifier)
+	}
+
+	if config.MailmapFile != "" {
+		identities, err := mailmap.Load(config.MailmapFile)
+		if err != nil {
+			log.Fatalf("Error loading mailmap: %v", err)
+		}
+		handler.SetMailmap(identities)
+	}
+
+	snapshots, err := store.Open(config.DataDir)
+	if err != nil {
+		log.Fatalf("Error opening snapshot store: %v", err)
+	}
+	handler.SetStore(snapshots)
+
+	tracked, err := cu.LoadTrackedTargets(config.TrackedFile)
+	if err != nil {
+		log.Fatalf("Error loading tracked targets: %v", err)
+	}
+	if len(config.SnapshotRepos) > 0 {
+		log.Printf("GITSTATS_SNAPSHOT_REPOS is deprecated, list the repositories in GITSTATS_TRACKED_FILE instead")
+	}
+	for _, repo := range config.SnapshotRepos {
+		if target := (cu.TrackedTarget{Repo: repo}); !slices.Contains(tracked, target) {
+			tracked = append(tracked, target)
+		}
+	}
+	collector, err := handler.NewCollector(config.CollectSchedule, config.GitHubToken, tracked)
+	if err != nil {
+		log.Fatalf("Error configuring collector: %v", err)
+	}
+	handler.SetCollector(collector)
+	go collector.Run(context.Background())
+
 	routes.SetupRoutes()
 	port := "8080"
 
@@ -16,3 +101,25 @@ func main() {
 		log.Fatalf("Error starting server: %v", err)
 	}
 }
+
+// newGitHubClient builds a shared GitHub client for the API at apiURL from
+// the server config.
+func newGitHubClient(config cu.ServerConfig, apiURL string) (*github.Client, error) {
+	opts := []github.Option{github.WithBaseURL(apiURL)}
+
+	switch config.CacheBackend {
+	case "memory":
+		opts = append(opts, github.WithCache(github.NewMemoryCache(config.CacheSize)))
+	case "disk":
+		cache, err := github.NewDiskCache(config.CacheDir)
+		if err != nil {
+			return nil, fmt.Errorf("error creating disk cache: %v", err)
+		}
+		opts = append(opts, github.WithCache(cache))
+	case "none":
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", config.CacheBackend)
+	}
+
+	return github.NewClient(opts...), nil
+}
@@ -0,0 +1,56 @@
+// Package pool runs independent pieces of work over a bounded number of
+// goroutines.
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+// Result is the outcome of one item of a Map call.
+type Result[R any] struct {
+	Value R
+	Err   error
+}
+
+// Map calls fn for every item using at most limit goroutines at a time and
+// returns the results in item order. A failing item does not stop the
+// others. Once ctx is cancelled no further items are started; those fail
+// with the context's error.
+func Map[T, R any](ctx context.Context, limit int, items []T, fn func(context.Context, T) (R, error)) []Result[R] {
+	if limit < 1 {
+		limit = 1
+	}
+
+	results := make([]Result[R], len(items))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		// A free slot and cancellation may race; never start work after
+		// the context is done.
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(items); j++ {
+				results[j].Err = err
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			value, err := fn(ctx, item)
+			results[i] = Result[R]{Value: value, Err: err}
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
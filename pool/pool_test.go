@@ -0,0 +1,75 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMapKeepsOrderAndErrors(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	errOdd := errors.New("odd")
+
+	results := Map(context.Background(), 2, items, func(ctx context.Context, n int) (int, error) {
+		if n%2 == 1 {
+			return 0, errOdd
+		}
+		return n * 10, nil
+	})
+
+	for i, result := range results {
+		if items[i]%2 == 1 {
+			if !errors.Is(result.Err, errOdd) {
+				t.Errorf("Item %d: expected error, got %v", items[i], result.Err)
+			}
+			continue
+		}
+		if result.Err != nil || result.Value != items[i]*10 {
+			t.Errorf("Item %d: unexpected result %+v", items[i], result)
+		}
+	}
+}
+
+func TestMapBoundsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	items := make([]int, 20)
+
+	Map(context.Background(), 3, items, func(ctx context.Context, _ int) (struct{}, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return struct{}{}, nil
+	})
+
+	if got := peak.Load(); got > 3 {
+		t.Errorf("Expected at most 3 concurrent calls, got %d", got)
+	}
+}
+
+func TestMapStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var started atomic.Int32
+	items := make([]int, 10)
+
+	results := Map(ctx, 1, items, func(ctx context.Context, _ int) (struct{}, error) {
+		if started.Add(1) == 2 {
+			cancel()
+		}
+		return struct{}{}, nil
+	})
+
+	if got := started.Load(); got != 2 {
+		t.Errorf("Expected 2 started items, got %d", got)
+	}
+	if !errors.Is(results[len(results)-1].Err, context.Canceled) {
+		t.Errorf("Expected remaining items to fail with context.Canceled, got %v", results[len(results)-1].Err)
+	}
+}
@@ -18,9 +18,14 @@ func SetupRoutes() {
 
 	// API endpoint
 	http.HandleFunc("/repo-stats", handler.HandleRepoStats)
+	http.HandleFunc("/compare-downloads", handler.HandleCompareDownloads)
+	http.HandleFunc("/release-cadence", handler.HandleReleaseCadence)
 	http.HandleFunc("/org-contributors", handler.HandleOrgContributors)
 	http.HandleFunc("/star-history", handler.HandleStarHistory)
+	http.HandleFunc("/activity-history", handler.HandleActivityHistory)
 	http.HandleFunc("/active-contributors", handler.HandleActiveContributors)
 	http.HandleFunc("/github-stargazers", handler.HandleStargazers)
+	http.HandleFunc("/repo-history", handler.HandleRepoHistory)
+	http.HandleFunc("/tracked", handler.HandleTracked)
 
 }
@@ -0,0 +1,175 @@
+// Package schedule parses cron-like schedule specifications.
+//
+// Supported forms are the five standard cron fields (minute, hour, day of
+// month, month, day of week) with *, lists, ranges and steps, the
+// descriptors @hourly, @daily (or @midnight), @weekly and @monthly, and
+// @every <duration> for fixed intervals such as "@every 6h".
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule yields the activation times of a specification.
+type Schedule interface {
+	// Next returns the first activation strictly after t, or the zero
+	// time if there is none.
+	Next(t time.Time) time.Time
+}
+
+// Parse parses spec into a Schedule.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval in %q: %v", spec, err)
+		}
+		if interval < time.Second {
+			return nil, fmt.Errorf("interval in %q must be at least one second", spec)
+		}
+		return every(interval), nil
+	}
+
+	switch spec {
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	var c cron
+	var err error
+	if c.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("invalid minute in %q: %v", spec, err)
+	}
+	if c.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("invalid hour in %q: %v", spec, err)
+	}
+	if c.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("invalid day of month in %q: %v", spec, err)
+	}
+	if c.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("invalid month in %q: %v", spec, err)
+	}
+	if c.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("invalid day of week in %q: %v", spec, err)
+	}
+	// Both 0 and 7 mean Sunday
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domStar = fields[2] == "*"
+	c.dowStar = fields[4] == "*"
+	// Reject specs such as "0 0 30 2 *" that name a day that never exists
+	if c.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("invalid schedule %q: never fires", spec)
+	}
+	return c, nil
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// cron holds one bit per allowed value of each field.
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+func (c cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// Any satisfiable schedule fires within nine years: Feb 29 can be
+	// eight years apart around a century that is not a leap year. Specs
+	// that never fire yield the zero time.
+	limit := t.AddDate(9, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches follows cron semantics: when both day fields are restricted,
+// a day matching either of them fires.
+func (c cron) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseField turns a cron field like "*/15", "1-5" or "0,30" into a bit set.
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			loPart, hiPart, isRange := strings.Cut(rangePart, "-")
+			n, err := strconv.Atoi(loPart)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", loPart)
+			}
+			lo, hi = n, n
+			if isRange {
+				if hi, err = strconv.Atoi(hiPart); err != nil {
+					return 0, fmt.Errorf("invalid value %q", hiPart)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("value out of range %d-%d in %q", min, max, part)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
@@ -0,0 +1,43 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	from := time.Date(2024, 1, 15, 10, 17, 30, 0, time.UTC) // a Monday
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"@every 90m", from.Add(90 * time.Minute)},
+		{"@hourly", time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, 1, 15, 13, 0, 0, 0, time.UTC)},
+		{"30 2 * * 0", time.Date(2024, 1, 21, 2, 30, 0, 0, time.UTC)},
+		{"30 2 * * 7", time.Date(2024, 1, 21, 2, 30, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 6 1,20 * 5", time.Date(2024, 1, 19, 6, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", tt.spec, err)
+			continue
+		}
+		if got := s.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Parse(%q).Next() = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "@every soon", "@every 1ms", "0 0 30 2 *", "0 0 31 4,6,9,11 *"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) expected error", spec)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Package semver parses release tags as semantic versions.
+//
+// Tags are matched leniently: a leading "v" and any prefix separated by a
+// dash or slash (as in "keploy-v1.2.3" or "cli/v1.2.3") are ignored, and
+// missing minor or patch numbers count as zero. Dates such as the one in
+// "nightly-2024-01-05" are not versions. Precedence follows
+// https://semver.org, ignoring build metadata.
+package semver
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version.
+type Version struct {
+	Major, Minor, Patch int
+	Prerelease          string
+	Build               string
+}
+
+var versionPattern = regexp.MustCompile(`(?:^|[-/_@])[vV]?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+// Parse extracts the version from tag. ok is false if tag does not end in
+// a version number.
+func Parse(tag string) (v Version, ok bool) {
+	m := versionPattern.FindStringSubmatch(tag)
+	if m == nil {
+		return Version{}, false
+	}
+	// A bare number followed by a numeric suffix is a date, not a major
+	// version with a prerelease
+	if m[2] == "" && m[4] != "" && m[4][0] >= '0' && m[4][0] <= '9' {
+		return Version{}, false
+	}
+
+	v.Major, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		v.Minor, _ = strconv.Atoi(m[2])
+	}
+	if m[3] != "" {
+		v.Patch, _ = strconv.Atoi(m[3])
+	}
+	v.Prerelease = m[4]
+	v.Build = m[5]
+	return v, true
+}
+
+// String formats v in canonical form with a leading "v".
+func (v Version) String() string {
+	s := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// IsPrerelease reports whether v carries a prerelease suffix such as -rc.1.
+func (v Version) IsPrerelease() bool {
+	return v.Prerelease != ""
+}
+
+// MajorLine names the release line of v's major version, e.g. "v1".
+func (v Version) MajorLine() string {
+	return fmt.Sprintf("v%d", v.Major)
+}
+
+// MinorLine names the release line of v's minor version, e.g. "v1.2".
+func (v Version) MinorLine() string {
+	return fmt.Sprintf("v%d.%d", v.Major, v.Minor)
+}
+
+// Compare returns -1, 0 or 1 depending on whether a has lower, equal or
+// higher precedence than b.
+func Compare(a, b Version) int {
+	if c := cmp.Compare(a.Major, b.Major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Minor, b.Minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Patch, b.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(a.Prerelease, b.Prerelease)
+}
+
+// comparePrerelease orders prerelease suffixes. A version without one ranks
+// above any prerelease of it; otherwise dot separated identifiers compare
+// numerically when both are numbers and lexically otherwise.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(an, bn)
+		case aErr == nil:
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(as[i], bs[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(as), len(bs))
+}
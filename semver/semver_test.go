@@ -0,0 +1,57 @@
+package semver
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want Version
+		ok   bool
+	}{
+		{"v1.2.3", Version{Major: 1, Minor: 2, Patch: 3}, true},
+		{"2.0.0-rc.1", Version{Major: 2, Prerelease: "rc.1"}, true},
+		{"v1.4", Version{Major: 1, Minor: 4}, true},
+		{"keploy-v0.9.1+build.7", Version{Minor: 9, Patch: 1, Build: "build.7"}, true},
+		{"cli/v3.1.0", Version{Major: 3, Minor: 1}, true},
+		{"nightly", Version{}, false},
+		{"release-2024", Version{Major: 2024}, true},
+		{"nightly-2024-01-05", Version{}, false},
+		{"v2-beta", Version{Major: 2, Prerelease: "beta"}, true},
+		{"v1.2.3.4", Version{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Parse(tt.tag)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Parse(%q) = %+v, %v, expected %+v, %v", tt.tag, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Each version ranks below the next, per the semver.org example
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0",
+	}
+	for i := 1; i < len(ordered); i++ {
+		a, _ := Parse(ordered[i-1])
+		b, _ := Parse(ordered[i])
+		if Compare(a, b) != -1 || Compare(b, a) != 1 {
+			t.Errorf("Expected %s < %s", ordered[i-1], ordered[i])
+		}
+	}
+
+	a, _ := Parse("v1.0.0+one")
+	b, _ := Parse("1.0.0+two")
+	if Compare(a, b) != 0 {
+		t.Errorf("Expected build metadata to be ignored")
+	}
+}
+
+func TestLines(t *testing.T) {
+	v, _ := Parse("v1.2.3-rc.1")
+	if v.MajorLine() != "v1" || v.MinorLine() != "v1.2" || !v.IsPrerelease() || v.String() != "v1.2.3-rc.1" {
+		t.Errorf("Unexpected lines for %+v: %s %s %s", v, v.MajorLine(), v.MinorLine(), v.String())
+	}
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+const trackedFile = "tracked.json"
+
+type result struct {
+	ComputedAt time.Time       `json:"computed_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func (s *Store) resultPath(kind, key string) string {
+	return filepath.Join(s.dir, "results", kind, url.PathEscape(key)+".json")
+}
+
+// PutResult replaces the precomputed response of the given kind (such as
+// "repo-stats") for key.
+func (s *Store) PutResult(kind, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error encoding result: %v", err)
+	}
+	payload, err := json.Marshal(result{ComputedAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		return fmt.Errorf("error encoding result: %v", err)
+	}
+
+	path := s.resultPath(kind, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating result directory: %v", err)
+	}
+	return writeFileAtomic(path, payload)
+}
+
+// Result decodes the precomputed response of the given kind for key into v
+// and reports when it was computed. ok is false when there is none.
+func (s *Store) Result(kind, key string, v any) (computedAt time.Time, ok bool, err error) {
+	payload, err := os.ReadFile(s.resultPath(kind, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("error reading result: %v", err)
+	}
+
+	var r result
+	if err := json.Unmarshal(payload, &r); err != nil {
+		return time.Time{}, false, fmt.Errorf("error decoding result: %v", err)
+	}
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		return time.Time{}, false, fmt.Errorf("error decoding result: %v", err)
+	}
+	return r.ComputedAt, true, nil
+}
+
+// DeleteResults removes the precomputed responses of every kind for key.
+func (s *Store) DeleteResults(key string) error {
+	kinds, err := os.ReadDir(filepath.Join(s.dir, "results"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading results: %v", err)
+	}
+
+	for _, kind := range kinds {
+		if !kind.IsDir() {
+			continue
+		}
+		err := os.Remove(s.resultPath(kind.Name(), key))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error deleting result: %v", err)
+		}
+	}
+	return nil
+}
+
+// Tracked returns the repositories and organizations registered for
+// background collection.
+func (s *Store) Tracked() ([]cu.TrackedTarget, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payload, err := os.ReadFile(filepath.Join(s.dir, trackedFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading tracked targets: %v", err)
+	}
+
+	var targets []cu.TrackedTarget
+	if err := json.Unmarshal(payload, &targets); err != nil {
+		return nil, fmt.Errorf("error decoding tracked targets: %v", err)
+	}
+	return targets, nil
+}
+
+// SetTracked replaces the registered targets.
+func (s *Store) SetTracked(targets []cu.TrackedTarget) error {
+	payload, err := json.MarshalIndent(targets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding tracked targets: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeFileAtomic(filepath.Join(s.dir, trackedFile), payload)
+}
+
+// writeFileAtomic writes through a temporary file so readers never observe
+// a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creating temporary file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
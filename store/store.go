@@ -0,0 +1,141 @@
+// Package store keeps historical repository metrics on the local disk. It
+// needs no external database: every repository gets one append-only JSON
+// Lines file of snapshots inside the data directory.
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+const snapshotExt = ".jsonl"
+
+// Store is a file-based snapshot store. It is safe for concurrent use
+// within one process.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// Open returns a Store rooted at dir, creating the directory if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating data directory: %v", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) path(repo string) string {
+	return filepath.Join(s.dir, url.PathEscape(repo)+snapshotExt)
+}
+
+// Append records a snapshot. Snapshots are keyed by their RepoName.
+func (s *Store) Append(snapshot cu.Snapshot) error {
+	if snapshot.RepoName == "" {
+		return errors.New("snapshot has no repository name")
+	}
+
+	line, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("error encoding snapshot: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path(snapshot.RepoName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening snapshot file: %v", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing snapshot: %v", err)
+	}
+	return f.Close()
+}
+
+// Snapshots returns the snapshots of repo taken within [from, to], oldest
+// first. A zero from or to leaves that side of the range open. Unknown
+// repositories yield no snapshots.
+func (s *Store) Snapshots(repo string, from, to time.Time) ([]cu.Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path(repo))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening snapshot file: %v", err)
+	}
+	defer f.Close()
+
+	var snapshots []cu.Snapshot
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var snapshot cu.Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snapshot); err != nil {
+			// A torn final line from a crash mid-write; skip it
+			continue
+		}
+		if !from.IsZero() && snapshot.Time.Before(from) {
+			continue
+		}
+		if !to.IsZero() && snapshot.Time.After(to) {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading snapshots: %v", err)
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.Before(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+// Latest returns the most recent snapshot of repo, if any.
+func (s *Store) Latest(repo string) (*cu.Snapshot, bool, error) {
+	snapshots, err := s.Snapshots(repo, time.Time{}, time.Time{})
+	if err != nil || len(snapshots) == 0 {
+		return nil, false, err
+	}
+	return &snapshots[len(snapshots)-1], true, nil
+}
+
+// Repos lists every repository with at least one snapshot.
+func (s *Store) Repos() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading data directory: %v", err)
+	}
+
+	var repos []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), snapshotExt)
+		if entry.IsDir() || !ok {
+			continue
+		}
+		repo, err := url.PathUnescape(name)
+		if err != nil {
+			continue
+		}
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	return repos, nil
+}
@@ -0,0 +1,132 @@
+package store
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	cu "github.com/keploy/gitstats/common"
+)
+
+func TestStoreAppendAndQuery(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		snapshot := cu.Snapshot{RepoName: "keploy/keploy", Time: base.AddDate(0, 0, i), Stars: 100 + i}
+		if err := s.Append(snapshot); err != nil {
+			t.Fatalf("Append returned error: %v", err)
+		}
+	}
+	if err := s.Append(cu.Snapshot{RepoName: "ghe.example.com/team/tool", Time: base, Stars: 1}); err != nil {
+		t.Fatalf("Append returned error: %v", err)
+	}
+
+	all, err := s.Snapshots("keploy/keploy", time.Time{}, time.Time{})
+	if err != nil || len(all) != 5 {
+		t.Fatalf("Expected 5 snapshots, got %d (%v)", len(all), err)
+	}
+
+	ranged, err := s.Snapshots("keploy/keploy", base.AddDate(0, 0, 1), base.AddDate(0, 0, 3))
+	if err != nil || len(ranged) != 3 || ranged[0].Stars != 101 {
+		t.Errorf("Unexpected ranged snapshots %+v (%v)", ranged, err)
+	}
+
+	latest, ok, err := s.Latest("keploy/keploy")
+	if err != nil || !ok || latest.Stars != 104 {
+		t.Errorf("Unexpected latest snapshot %+v (%v)", latest, err)
+	}
+
+	repos, err := s.Repos()
+	if err != nil || len(repos) != 2 || repos[0] != "ghe.example.com/team/tool" || repos[1] != "keploy/keploy" {
+		t.Errorf("Unexpected repos %v (%v)", repos, err)
+	}
+}
+
+func TestStoreSkipsTornLines(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	if err := s.Append(cu.Snapshot{RepoName: "a/b", Time: time.Now(), Stars: 1}); err != nil {
+		t.Fatalf("Append returned error: %v", err)
+	}
+
+	f, err := os.OpenFile(s.path("a/b"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("Error opening snapshot file: %v", err)
+	}
+	f.Write([]byte(`{"repo_name":"a/b","ti`))
+	f.Close()
+
+	snapshots, err := s.Snapshots("a/b", time.Time{}, time.Time{})
+	if err != nil || len(snapshots) != 1 {
+		t.Errorf("Expected 1 snapshot, got %d (%v)", len(snapshots), err)
+	}
+}
+
+func TestStoreUnknownRepo(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	if _, ok, err := s.Latest("nobody/nothing"); ok || err != nil {
+		t.Errorf("Expected no snapshot, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestStoreResults(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+
+	var stats cu.DownloadStats
+	if _, ok, err := s.Result("repo-stats", "keploy/keploy", &stats); ok || err != nil {
+		t.Errorf("Expected no result, got ok=%v err=%v", ok, err)
+	}
+
+	if err := s.PutResult("repo-stats", "keploy/keploy", cu.DownloadStats{RepoName: "keploy/keploy", TotalDownloads: 42}); err != nil {
+		t.Fatalf("PutResult returned error: %v", err)
+	}
+	computedAt, ok, err := s.Result("repo-stats", "keploy/keploy", &stats)
+	if !ok || err != nil || stats.TotalDownloads != 42 || computedAt.IsZero() {
+		t.Errorf("Unexpected result %+v at %v (ok=%v err=%v)", stats, computedAt, ok, err)
+	}
+
+	// Results must not show up as snapshotted repositories
+	if repos, err := s.Repos(); err != nil || len(repos) != 0 {
+		t.Errorf("Expected no repos, got %v (%v)", repos, err)
+	}
+
+	if err := s.PutResult("star-history", "keploy/keploy", cu.StarHistory{RepoName: "keploy/keploy"}); err != nil {
+		t.Fatalf("PutResult returned error: %v", err)
+	}
+	if err := s.DeleteResults("keploy/keploy"); err != nil {
+		t.Fatalf("DeleteResults returned error: %v", err)
+	}
+	for _, kind := range []string{"repo-stats", "star-history"} {
+		if _, ok, err := s.Result(kind, "keploy/keploy", &stats); ok || err != nil {
+			t.Errorf("Expected %s to be deleted, got ok=%v err=%v", kind, ok, err)
+		}
+	}
+}
+
+func TestStoreTracked(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+
+	targets := []cu.TrackedTarget{{Repo: "https://github.com/keploy/keploy"}, {Org: "keploy"}}
+	if err := s.SetTracked(targets); err != nil {
+		t.Fatalf("SetTracked returned error: %v", err)
+	}
+	got, err := s.Tracked()
+	if err != nil || len(got) != 2 || got[0] != targets[0] || got[1] != targets[1] {
+		t.Errorf("Expected %v, got %v (%v)", targets, got, err)
+	}
+}